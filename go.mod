@@ -5,7 +5,9 @@ go 1.23.0
 toolchain go1.23.7
 
 require (
+	github.com/dlclark/regexp2 v1.11.4
 	github.com/pb33f/libopenapi v0.21.8
+	github.com/santhosh-tekuri/jsonschema/v6 v6.0.2
 	golang.org/x/text v0.23.0
 	gopkg.in/yaml.v3 v3.0.1
 )
@@ -0,0 +1,558 @@
+package main
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io/ioutil"
+	"net/url"
+	"path"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// filhoBundle descreve o tipo de objeto OpenAPI encontrado em uma chave: um único objeto, um mapa ou uma lista
+type filhoBundle struct {
+	tipo    string
+	colecao string // "", "mapa" ou "lista"
+}
+
+// tipos de objeto percorridos pelo bundle; os tipos com o nome de uma seção de components podem virar componentes
+var filhosPorTipo = map[string]map[string]filhoBundle{
+	"documento": {
+		"paths":      {"pathItems", "mapa"},
+		"webhooks":   {"pathItems", "mapa"},
+		"components": {"components", ""},
+	},
+	"components": {
+		"schemas":         {"schemas", "mapa"},
+		"responses":       {"responses", "mapa"},
+		"parameters":      {"parameters", "mapa"},
+		"examples":        {"examples", "mapa"},
+		"requestBodies":   {"requestBodies", "mapa"},
+		"headers":         {"headers", "mapa"},
+		"securitySchemes": {"securitySchemes", "mapa"},
+		"links":           {"links", "mapa"},
+		"callbacks":       {"callbacks", "mapa"},
+		"pathItems":       {"pathItems", "mapa"},
+	},
+	"pathItems": {
+		"parameters": {"parameters", "lista"},
+		"get":        {"operacao", ""}, "put": {"operacao", ""}, "post": {"operacao", ""}, "delete": {"operacao", ""},
+		"options": {"operacao", ""}, "head": {"operacao", ""}, "patch": {"operacao", ""}, "trace": {"operacao", ""},
+	},
+	"operacao": {
+		"parameters":  {"parameters", "lista"},
+		"requestBody": {"requestBodies", ""},
+		"responses":   {"responses", "mapa"},
+		"callbacks":   {"callbacks", "mapa"},
+	},
+	"callbacks": {
+		"*": {"pathItems", ""},
+	},
+	"parameters": {
+		"schema":   {"schemas", ""},
+		"content":  {"mediaType", "mapa"},
+		"examples": {"examples", "mapa"},
+	},
+	"headers": {
+		"schema":   {"schemas", ""},
+		"content":  {"mediaType", "mapa"},
+		"examples": {"examples", "mapa"},
+	},
+	"requestBodies": {
+		"content": {"mediaType", "mapa"},
+	},
+	"responses": {
+		"headers": {"headers", "mapa"},
+		"content": {"mediaType", "mapa"},
+		"links":   {"links", "mapa"},
+	},
+	"mediaType": {
+		"schema":   {"schemas", ""},
+		"examples": {"examples", "mapa"},
+		"encoding": {"encoding", "mapa"},
+	},
+	"encoding": {
+		"headers": {"headers", "mapa"},
+	},
+	"schemas": {
+		"properties": {"schemas", "mapa"}, "patternProperties": {"schemas", "mapa"}, "definitions": {"schemas", "mapa"},
+		"$defs": {"schemas", "mapa"}, "dependentSchemas": {"schemas", "mapa"},
+		"items": {"schemas", ""}, "additionalProperties": {"schemas", ""}, "not": {"schemas", ""},
+		"contains": {"schemas", ""}, "if": {"schemas", ""}, "then": {"schemas", ""}, "else": {"schemas", ""},
+		"propertyNames": {"schemas", ""}, "unevaluatedItems": {"schemas", ""}, "unevaluatedProperties": {"schemas", ""},
+		"additionalItems": {"schemas", ""},
+		"allOf":           {"schemas", "lista"}, "anyOf": {"schemas", "lista"}, "oneOf": {"schemas", "lista"}, "prefixItems": {"schemas", "lista"},
+	},
+}
+
+var nomeDeComponenteInvalido = regexp.MustCompile(`[^a-zA-Z0-9._-]+`)
+
+// bundler junta os documentos referenciados no documento principal: cada $ref externo vira um componente
+// com $ref local, mantendo o nome original, reaproveitando componentes idênticos e renomeando colisões
+type bundler struct {
+	opcoes     opcoesReferencias
+	principal  string // localização usada para resolver os $refs relativos do documento principal
+	versao     versaoSpec
+	components *yaml.Node
+	documentos map[string]*yaml.Node // documentos externos já carregados
+	importados map[string]string     // localização#fragmento -> $ref local do componente
+	hashes     map[string]string     // seção + hash do conteúdo -> nome do componente
+	vistos     map[*yaml.Node]bool
+	avisos     []string
+	erros      []error
+}
+
+// Função para criar o bundle de um documento OpenAPI 3.x, alterando a árvore de nós recebida
+func bundleDocumento(rootNode *yaml.Node, specFile string, opcoes opcoesReferencias) ([]string, error) {
+	doc := documento(rootNode)
+	versao, valor := detectarVersao(doc)
+	if versao != versaoOAS3_0 && versao != versaoOAS3_1 {
+		return nil, fmt.Errorf("o bundle suporta apenas OpenAPI 3.x (versão %q); use o comando convert para Swagger 2.0", valor)
+	}
+
+	baseDir := opcoes.baseDir
+	if baseDir == "" {
+		baseDir = filepath.Dir(specFile)
+	}
+	principal, err := filepath.Abs(filepath.Join(baseDir, filepath.Base(specFile)))
+	if err != nil {
+		return nil, fmt.Errorf("erro ao obter o caminho de %s: %v", specFile, err)
+	}
+
+	b := &bundler{
+		opcoes:     opcoes,
+		principal:  principal,
+		versao:     versao,
+		documentos: make(map[string]*yaml.Node),
+		importados: make(map[string]string),
+		hashes:     make(map[string]string),
+		vistos:     make(map[*yaml.Node]bool),
+	}
+	b.components = resolverAlias(valorDoMapa(doc, "components"))
+	if b.components == nil {
+		b.components = novoMapa()
+		definirValor(doc, "components", b.components)
+	}
+
+	// os componentes já existentes no documento principal também são reaproveitados; os que são apenas um
+	// $ref externo recebem o conteúdo importado, mantendo o seu nome
+	for _, secao := range paresDoMapa(b.components) {
+		for _, componente := range paresDoMapa(secao.valor) {
+			ref, ok := texto(valorDoMapa(componente.valor, "$ref"))
+			if !ok {
+				b.hashes[secao.chave.Value+":"+hashDoNode(componente.valor)] = componente.chave.Value
+				continue
+			}
+			if destino := b.destino(ref, ""); destino != "" && destino != b.principal {
+				b.importados[destino+"#"+fragmentoDaRef(ref)] = "#/components/" + secao.chave.Value + "/" + escaparPonteiro(componente.chave.Value)
+			}
+		}
+	}
+
+	// os componentes que recebem conteúdo importado são preenchidos primeiro, para que os demais $refs
+	// para o mesmo conteúdo os reaproveitem
+	for _, secao := range paresDoMapa(b.components) {
+		for _, componente := range paresDoMapa(secao.valor) {
+			b.percorrerComponente(componente.valor, secao.chave.Value, componente.chave.Value)
+		}
+	}
+	b.percorrer(doc, "documento", "")
+	if len(b.erros) > 0 {
+		return b.avisos, fmt.Errorf("não foi possível montar o bundle: %v", b.erros[0])
+	}
+	if vazio(b.components) {
+		removerChave(doc, "components")
+	}
+	return b.avisos, nil
+}
+
+// Função para percorrer um objeto do tipo informado, importando os $refs externos; localizacao é o documento
+// externo de onde o objeto veio, ou vazio para o documento principal
+func (b *bundler) percorrer(node *yaml.Node, tipo, localizacao string) {
+	node = resolverAlias(node)
+	if node == nil || node.Kind != yaml.MappingNode || b.vistos[node] {
+		return
+	}
+	b.vistos[node] = true
+
+	if ref, ok := texto(valorDoMapa(node, "$ref")); ok {
+		b.reescreverRef(node, ref, tipo, localizacao)
+		return
+	}
+
+	filhos := filhosPorTipo[tipo]
+	for i := 0; i+1 < len(node.Content); i += 2 {
+		filho, ok := filhos[node.Content[i].Value]
+		if !ok {
+			if filho, ok = filhos["*"]; !ok {
+				continue
+			}
+		}
+		valor := resolverAlias(node.Content[i+1])
+		switch {
+		case tipo == "components" && localizacao == "":
+			for _, par := range paresDoMapa(valor) {
+				b.percorrerComponente(par.valor, filho.tipo, par.chave.Value)
+			}
+		case filho.colecao == "mapa":
+			for _, par := range paresDoMapa(valor) {
+				b.percorrer(par.valor, filho.tipo, localizacao)
+			}
+		case filho.colecao == "lista":
+			for _, item := range itensDaLista(valor) {
+				b.percorrer(item, filho.tipo, localizacao)
+			}
+		default:
+			b.percorrer(valor, filho.tipo, localizacao)
+		}
+	}
+}
+
+// Função para percorrer um componente do documento principal; um componente que é apenas um $ref externo
+// (schemas: {Payment: {$ref: ./Payment.yaml}}) recebe o conteúdo importado no seu lugar
+func (b *bundler) percorrerComponente(node *yaml.Node, secao, nome string) {
+	node = resolverAlias(node)
+	ref, ok := texto(valorDoMapa(node, "$ref"))
+	destino := b.destino(ref, "")
+	proprio := "#/components/" + secao + "/" + escaparPonteiro(nome)
+	if !ok || destino == "" || destino == b.principal || b.vistos[node] || b.importados[destino+"#"+fragmentoDaRef(ref)] != proprio {
+		b.percorrer(node, secao, "")
+		return
+	}
+	b.vistos[node] = true
+
+	alvo, err := b.buscar(destino, fragmentoDaRef(ref))
+	if err != nil {
+		b.erros = append(b.erros, err)
+		return
+	}
+	*node = *copiarNode(alvo)
+	b.percorrer(node, secao, destino)
+	if hash := secao + ":" + hashDoNode(node); b.hashes[hash] == "" {
+		b.hashes[hash] = nome
+	}
+}
+
+// Função para calcular o documento de destino de um $ref escrito em localizacao (vazio para o documento
+// principal); retorna vazio para referências internas do documento principal
+func (b *bundler) destino(ref, localizacao string) string {
+	alvo := semFragmento(ref)
+	if alvo == "" && localizacao == "" {
+		return ""
+	}
+	base := b.principal
+	if localizacao != "" {
+		base = localizacao
+	}
+	if alvo == "" {
+		return base
+	}
+	return resolverLocalizacao(base, alvo)
+}
+
+// Função para reescrever um $ref: referências externas viram componentes (ou são incorporadas quando o tipo
+// não pode ser um componente) e referências internas de documentos externos apontam para o componente importado
+func (b *bundler) reescreverRef(node *yaml.Node, ref, tipo, localizacao string) {
+	destino, fragmento := b.destino(ref, localizacao), fragmentoDaRef(ref)
+	if destino == "" {
+		return
+	}
+	if destino == b.principal {
+		definirValor(node, "$ref", novoTexto("#"+fragmento))
+		return
+	}
+
+	importado, inline := b.importar(destino, fragmento, tipo)
+	if inline != nil {
+		// objetos que não podem ser componentes são copiados no lugar do $ref
+		*node = *inline
+		return
+	}
+	if importado != "" {
+		definirValor(node, "$ref", novoTexto(importado))
+	}
+}
+
+// Função para importar o objeto indicado por um $ref externo; retorna o $ref local do componente ou,
+// quando o tipo não pode ser um componente, a cópia do objeto já com os $refs reescritos
+func (b *bundler) importar(localizacao, fragmento, tipo string) (string, *yaml.Node) {
+	chave := localizacao + "#" + fragmento
+	if ref, ok := b.importados[chave]; ok {
+		return ref, nil
+	}
+
+	alvo, err := b.buscar(localizacao, fragmento)
+	if err != nil {
+		b.erros = append(b.erros, err)
+		return "", nil
+	}
+
+	secao := tipo
+	if _, ok := filhosPorTipo["components"][secao]; !ok || (secao == "pathItems" && b.versao != versaoOAS3_1) {
+		copia := copiarNode(alvo)
+		b.percorrer(copia, tipo, localizacao)
+		return "", copia
+	}
+
+	mapaSecao := resolverAlias(valorDoMapa(b.components, secao))
+	if mapaSecao == nil {
+		mapaSecao = novoMapa()
+		definirValor(b.components, secao, mapaSecao)
+	}
+	sugerido := nomeSugerido(localizacao, fragmento)
+	nome := sugerido
+	for n := 2; temChave(mapaSecao, nome); n++ {
+		nome = fmt.Sprintf("%s_%d", sugerido, n)
+	}
+	ref := "#/components/" + secao + "/" + escaparPonteiro(nome)
+	b.importados[chave] = ref
+
+	// o nome é reservado antes de percorrer o conteúdo, para que referências circulares apontem para ele
+	copia := copiarNode(alvo)
+	definirValor(mapaSecao, nome, copia)
+	b.percorrer(copia, tipo, localizacao)
+
+	hash := secao + ":" + hashDoNode(copia)
+	if existente, ok := b.hashes[hash]; ok && !contemTexto(coletarRefs(copia, nil), ref) {
+		removerChave(mapaSecao, nome)
+		ref = "#/components/" + secao + "/" + escaparPonteiro(existente)
+		b.importados[chave] = ref
+		return ref, nil
+	}
+	b.hashes[hash] = nome
+	if nome != sugerido {
+		origem := caminhoRelativo(localizacao, filepath.Dir(b.principal))
+		if fragmento != "" {
+			origem += "#" + fragmento
+		}
+		b.avisos = append(b.avisos, fmt.Sprintf("%s importado como %s, pois já existe outro %s/%s", origem, nome, secao, sugerido))
+	}
+	return ref, nil
+}
+
+// Função para buscar o objeto indicado por um documento externo e um fragmento (JSON pointer)
+func (b *bundler) buscar(localizacao, fragmento string) (*yaml.Node, error) {
+	doc, err := b.carregar(localizacao)
+	if err != nil {
+		return nil, err
+	}
+	alvo := nodeDoPonteiro(doc, fragmento)
+	if alvo == nil {
+		return nil, fmt.Errorf("o fragmento #%s não existe em %s", fragmento, localizacao)
+	}
+	return alvo, nil
+}
+
+// Função para carregar um documento externo (arquivo local ou URL mapeada em --ref-map)
+func (b *bundler) carregar(localizacao string) (*yaml.Node, error) {
+	if doc, ok := b.documentos[localizacao]; ok {
+		return doc, nil
+	}
+
+	var data []byte
+	var err error
+	if ehURL(localizacao) {
+		if b.opcoes.mapa == nil {
+			return nil, fmt.Errorf("o documento remoto %s exige um mapeamento em --ref-map", localizacao)
+		}
+		if data, err = b.opcoes.mapa.ler(localizacao); err == nil {
+			data, err = convertToUTF8(data)
+		}
+	} else {
+		data, err = readFile(localizacao)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	doc, _, err := lerDocumento(data)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %v", localizacao, err)
+	}
+	b.documentos[localizacao] = doc
+	return doc, nil
+}
+
+// Função para obter o fragmento (JSON pointer) de um $ref, decodificando caracteres escapados na URI
+func fragmentoDaRef(ref string) string {
+	i := strings.Index(ref, "#")
+	if i < 0 {
+		return ""
+	}
+	if fragmento, err := url.PathUnescape(ref[i+1:]); err == nil {
+		return fragmento
+	}
+	return ref[i+1:]
+}
+
+// Função para sugerir o nome do componente: o último segmento do fragmento ou, sem fragmento, o nome do arquivo
+func nomeSugerido(localizacao, ponteiro string) string {
+	nome := ""
+	if segmentos := strings.Split(strings.Trim(ponteiro, "/"), "/"); segmentos[len(segmentos)-1] != "" {
+		nome = strings.ReplaceAll(strings.ReplaceAll(segmentos[len(segmentos)-1], "~1", "/"), "~0", "~")
+	} else {
+		nome = path.Base(filepath.ToSlash(localizacao))
+		for ext := path.Ext(nome); ext != ""; ext = path.Ext(nome) {
+			nome = strings.TrimSuffix(nome, ext)
+		}
+	}
+	nome = nomeDeComponenteInvalido.ReplaceAllString(nome, "_")
+	if nome == "" {
+		nome = "Componente"
+	}
+	return nome
+}
+
+// Função para copiar um nó em profundidade, expandindo aliases e merge keys
+func copiarNode(node *yaml.Node) *yaml.Node {
+	return copiarNodeEm(node, make(map[*yaml.Node]bool))
+}
+
+func copiarNodeEm(node *yaml.Node, emAndamento map[*yaml.Node]bool) *yaml.Node {
+	node = resolverAlias(node)
+	if node == nil || emAndamento[node] {
+		// aliases recursivos não têm representação sem âncoras
+		return &yaml.Node{Kind: yaml.ScalarNode, Tag: "!!null", Value: "null"}
+	}
+	emAndamento[node] = true
+	defer delete(emAndamento, node)
+
+	copia := *node
+	copia.Anchor = ""
+	copia.Content = nil
+	switch node.Kind {
+	case yaml.DocumentNode:
+		if doc := documento(node); doc != nil {
+			return copiarNodeEm(doc, emAndamento)
+		}
+		return novoMapa()
+	case yaml.MappingNode:
+		for _, par := range paresDoMapa(node) {
+			chave := *par.chave
+			copia.Content = append(copia.Content, &chave, copiarNodeEm(par.valor, emAndamento))
+		}
+	case yaml.SequenceNode:
+		for _, item := range node.Content {
+			copia.Content = append(copia.Content, copiarNodeEm(item, emAndamento))
+		}
+	}
+	return &copia
+}
+
+// Função para calcular o hash do conteúdo normalizado de um nó: chaves ordenadas, aliases expandidos
+// e escalares comparados pelo valor JSON, de modo que a formatação do YAML não altera o resultado
+func hashDoNode(node *yaml.Node) string {
+	var buf bytes.Buffer
+	normalizarNode(&buf, node, make(map[*yaml.Node]bool))
+	soma := sha256.Sum256(buf.Bytes())
+	return hex.EncodeToString(soma[:])
+}
+
+func normalizarNode(buf *bytes.Buffer, node *yaml.Node, emAndamento map[*yaml.Node]bool) {
+	node = resolverAlias(node)
+	if node == nil || emAndamento[node] {
+		buf.WriteString("null")
+		return
+	}
+	emAndamento[node] = true
+	defer delete(emAndamento, node)
+
+	switch node.Kind {
+	case yaml.DocumentNode:
+		normalizarNode(buf, documento(node), emAndamento)
+	case yaml.MappingNode:
+		pares := paresDoMapa(node)
+		sort.Slice(pares, func(i, j int) bool { return pares[i].chave.Value < pares[j].chave.Value })
+		buf.WriteByte('{')
+		for i, par := range pares {
+			if i > 0 {
+				buf.WriteByte(',')
+			}
+			fmt.Fprintf(buf, "%q:", par.chave.Value)
+			normalizarNode(buf, par.valor, emAndamento)
+		}
+		buf.WriteByte('}')
+	case yaml.SequenceNode:
+		buf.WriteByte('[')
+		for i, item := range node.Content {
+			if i > 0 {
+				buf.WriteByte(',')
+			}
+			normalizarNode(buf, item, emAndamento)
+		}
+		buf.WriteByte(']')
+	case yaml.ScalarNode:
+		buf.Write(escalarJSON(node))
+	}
+}
+
+// Função para remover uma chave de um mapping
+func removerChave(mapa *yaml.Node, chave string) {
+	for i := 0; i+1 < len(mapa.Content); i += 2 {
+		if mapa.Content[i].Value == chave {
+			mapa.Content = append(mapa.Content[:i], mapa.Content[i+2:]...)
+			return
+		}
+	}
+}
+
+// Função para montar o bundle de um arquivo OpenAPI e salvar em YAML ou JSON
+func bundleOpenAPI(inputFile, outputFile string, formatacao opcoesSaida, opcoes opcoesReferencias) error {
+	data, err := readFile(inputFile)
+	if err != nil {
+		return err
+	}
+
+	rootNode, formatoEntrada, err := lerDocumento(data)
+	if err != nil {
+		return err
+	}
+
+	saida, err := escolherFormatoSaida(formatacao.formato, outputFile, formatoEntrada)
+	if err != nil {
+		return err
+	}
+	indentacao, err := escolherIndentacao(formatacao.indentacao, data)
+	if err != nil {
+		return err
+	}
+
+	// Verificar as referências antes de montar o bundle, para reportar todos os problemas com a localização
+	rolodex, err := novoRolodex(rootNode, inputFile, opcoes)
+	if err != nil {
+		return err
+	}
+	if referencias := errosDeReferencia(rolodex); len(referencias) > 0 {
+		localizarFindings(rootNode, referencias)
+		for _, err := range referencias {
+			fmt.Println("❌ Erro de referência:", err)
+		}
+		return fmt.Errorf("erro ao indexar as referências de %s", inputFile)
+	}
+
+	avisos, err := bundleDocumento(rootNode, inputFile, opcoes)
+	if err != nil {
+		return err
+	}
+	for _, aviso := range avisos {
+		fmt.Println("⚠️", aviso)
+	}
+
+	bundle, err := codificarDocumento(rootNode, saida, indentacao)
+	if err != nil {
+		return err
+	}
+	if err := ioutil.WriteFile(outputFile, bundle, 0644); err != nil {
+		return fmt.Errorf("erro ao salvar o bundle: %v", err)
+	}
+
+	fmt.Println("Bundle salvo em:", outputFile)
+	return nil
+}
@@ -0,0 +1,176 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+)
+
+// comandos disponíveis na linha de comando, além do modo padrão (validar e resolver os dois swaggers)
+var comandos = map[string]func(args []string) error{
+	"validate":    comandoValidate,
+	"resolve":     comandoResolve,
+	"bundle":      comandoBundle,
+	"prune":       comandoPrune,
+	"convert":     comandoConvert,
+	"vendor-refs": comandoVendorRefs,
+}
+
+// Função para registrar as flags de formatação da saída (--output-format e --indent)
+func flagsDeSaida(flags *flag.FlagSet) *opcoesSaida {
+	formatacao := &opcoesSaida{}
+	flags.StringVar(&formatacao.formato, "output-format", "", "formato do arquivo de saída (json ou yaml); por padrão usa a extensão do arquivo")
+	flags.IntVar(&formatacao.indentacao, "indent", 0, "espaços por nível de indentação; por padrão mantém a indentação do arquivo de entrada")
+	return formatacao
+}
+
+// Função para registrar as flags de localização das referências (--base-dir e --ref-map); a função
+// retornada monta as opções depois do parse
+func flagsDeReferencias(flags *flag.FlagSet) func() (opcoesReferencias, error) {
+	baseDir := flags.String("base-dir", "", "diretório base para resolver $refs a arquivos; por padrão usa o diretório do documento")
+	mapa := flags.String("ref-map", "", "arquivo de mapeamento de URLs para arquivos locais, usado nos $refs remotos")
+	return func() (opcoesReferencias, error) {
+		opcoes := opcoesReferencias{baseDir: *baseDir}
+		if *mapa != "" {
+			m, err := carregarMapaReferencias(*mapa)
+			if err != nil {
+				return opcoes, err
+			}
+			opcoes.mapa = m
+		}
+		return opcoes, nil
+	}
+}
+
+// Função para registrar a flag --circular-refs, usada pelos comandos que reportam referências circulares
+func flagDeCirculares(flags *flag.FlagSet) func() (politicaCircular, error) {
+	politica := flags.String("circular-refs", string(circularAvisar), "tratamento das referências circulares: allow, warn ou error; ciclos infinitos são sempre erros")
+	return func() (politicaCircular, error) {
+		return parsePoliticaCircular(*politica)
+	}
+}
+
+// Função para o comando validate: valida um único documento com as regras personalizadas
+func comandoValidate(args []string) error {
+	flags := flag.NewFlagSet("validate", flag.ContinueOnError)
+	referencias := flagsDeReferencias(flags)
+	circulares := flagDeCirculares(flags)
+	anterior := flags.String("previous", "", "versão anterior do documento, comparada pelas regras de mudanças (ex.: enum-changes)")
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+	if flags.NArg() != 2 {
+		return fmt.Errorf("uso: validate [--circular-refs allow|warn|error] [--previous anterior.yaml] [--base-dir dir] [--ref-map refs.yaml] swagger.yaml pb33f_rules.yaml")
+	}
+	opcoes, err := referencias()
+	if err != nil {
+		return err
+	}
+	if opcoes.circulares, err = circulares(); err != nil {
+		return err
+	}
+
+	return validateOpenAPIWithRules(flags.Arg(0), flags.Arg(1), opcoes, *anterior)
+}
+
+// Função para o comando resolve: resolve todas as referências e salva em YAML ou JSON
+func comandoResolve(args []string) error {
+	flags := flag.NewFlagSet("resolve", flag.ContinueOnError)
+	formatacao := flagsDeSaida(flags)
+	referencias := flagsDeReferencias(flags)
+	circulares := flagDeCirculares(flags)
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+	if flags.NArg() != 2 {
+		return fmt.Errorf("uso: resolve [--circular-refs allow|warn|error] [--output-format json|yaml] [--indent n] [--base-dir dir] [--ref-map refs.yaml] entrada saida")
+	}
+	opcoes, err := referencias()
+	if err != nil {
+		return err
+	}
+	if opcoes.circulares, err = circulares(); err != nil {
+		return err
+	}
+
+	return resolveOpenAPI(flags.Arg(0), flags.Arg(1), *formatacao, opcoes)
+}
+
+// Função para o comando bundle: junta os arquivos referenciados em components, mantendo $refs locais
+func comandoBundle(args []string) error {
+	flags := flag.NewFlagSet("bundle", flag.ContinueOnError)
+	formatacao := flagsDeSaida(flags)
+	referencias := flagsDeReferencias(flags)
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+	if flags.NArg() != 2 {
+		return fmt.Errorf("uso: bundle [--output-format json|yaml] [--indent n] [--base-dir dir] [--ref-map refs.yaml] entrada saida")
+	}
+	opcoes, err := referencias()
+	if err != nil {
+		return err
+	}
+
+	return bundleOpenAPI(flags.Arg(0), flags.Arg(1), *formatacao, opcoes)
+}
+
+// Função para o comando prune: remove os componentes que nenhuma operação usa, direta ou indiretamente
+func comandoPrune(args []string) error {
+	flags := flag.NewFlagSet("prune", flag.ContinueOnError)
+	dryRun := flags.Bool("dry-run", false, "apenas lista os componentes não usados, sem gerar o arquivo de saída")
+	formatacao := flagsDeSaida(flags)
+	referencias := flagsDeReferencias(flags)
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+	if flags.NArg() != 2 && !(*dryRun && flags.NArg() == 1) {
+		return fmt.Errorf("uso: prune [--dry-run] [--output-format json|yaml] [--indent n] [--base-dir dir] [--ref-map refs.yaml] entrada [saida]")
+	}
+	opcoes, err := referencias()
+	if err != nil {
+		return err
+	}
+
+	return pruneOpenAPI(flags.Arg(0), flags.Arg(1), *dryRun, *formatacao, opcoes)
+}
+
+// Função para o comando convert: converte um Swagger 2.0 em OpenAPI 3.0 e lista o que não pôde ser convertido
+func comandoConvert(args []string) error {
+	flags := flag.NewFlagSet("convert", flag.ContinueOnError)
+	formatacao := flagsDeSaida(flags)
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+	if flags.NArg() != 2 {
+		return fmt.Errorf("uso: convert [--output-format json|yaml] [--indent n] swagger2.yaml openapi3.yaml")
+	}
+
+	avisos, err := convertOpenAPI(flags.Arg(0), flags.Arg(1), *formatacao)
+	if err != nil {
+		return err
+	}
+	for _, aviso := range avisos {
+		fmt.Println("⚠️ Não convertido:", aviso)
+	}
+	fmt.Println("✅ Documento convertido para OpenAPI", versaoConvertida+":", flags.Arg(1))
+	return nil
+}
+
+// Função para o comando vendor-refs: salva no cache os documentos remotos referenciados, para que as
+// próximas execuções com --ref-map funcionem sem rede
+func comandoVendorRefs(args []string) error {
+	flags := flag.NewFlagSet("vendor-refs", flag.ContinueOnError)
+	referencias := flagsDeReferencias(flags)
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+	opcoes, err := referencias()
+	if err != nil {
+		return err
+	}
+	if flags.NArg() == 0 || opcoes.mapa == nil {
+		return fmt.Errorf("uso: vendor-refs --ref-map refs.yaml [--base-dir dir] swagger.yaml...")
+	}
+
+	return vendorizarReferencias(flags.Args(), opcoes.baseDir, opcoes.mapa)
+}
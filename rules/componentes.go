@@ -0,0 +1,339 @@
+package main
+
+import (
+	"fmt"
+	"io/ioutil"
+	"net/url"
+	"strings"
+
+	"github.com/pb33f/libopenapi/index"
+	"gopkg.in/yaml.v3"
+)
+
+// seções de componentes reutilizáveis: em "components" no OpenAPI 3 e na raiz do documento no Swagger 2.0
+var (
+	secoesComponentesOAS3 = []string{"schemas", "responses", "parameters", "examples", "requestBodies", "headers", "securitySchemes", "links", "callbacks", "pathItems"}
+	secoesComponentesOAS2 = []string{"definitions", "parameters", "responses", "securityDefinitions"}
+)
+
+// componente representa um componente reutilizável declarado no documento
+type componente struct {
+	ponteiro string     // JSON pointer do componente, ex.: #/components/schemas/Pessoa
+	secao    *yaml.Node // mapping da seção, de onde o componente é removido
+	nome     string
+	node     *yaml.Node
+}
+
+// Função para listar os componentes declarados no documento, na ordem em que aparecem
+func listarComponentes(doc *yaml.Node) []componente {
+	var componentes []componente
+	adicionar := func(secao *yaml.Node, prefixo string) {
+		for _, par := range paresDoMapa(secao) {
+			componentes = append(componentes, componente{prefixo + escaparPonteiro(par.chave.Value), secao, par.chave.Value, par.valor})
+		}
+	}
+	if versao, _ := detectarVersao(doc); versao == versaoOAS2 {
+		for _, secao := range secoesComponentesOAS2 {
+			adicionar(valorDoMapa(doc, secao), "#/"+secao+"/")
+		}
+		return componentes
+	}
+	components := valorDoMapa(doc, "components")
+	for _, secao := range secoesComponentesOAS3 {
+		adicionar(valorDoMapa(components, secao), "#/components/"+secao+"/")
+	}
+	return componentes
+}
+
+// usoComponentes monta o grafo de uso dos componentes e marca os alcançáveis a partir da raiz do documento.
+// As arestas de $ref vêm das referências registradas pelo índice de cada arquivo do rolodex, inclusive as de
+// arquivos externos que apontam de volta para componentes do documento; o percurso dos nós fica restrito aos
+// usos que o índice não registra: mappings de discriminator, requisitos de segurança e aliases YAML. O grafo
+// é necessário, e não apenas o conjunto de $refs do índice, para que componentes usados somente por outros
+// componentes não usados também sejam reportados
+type usoComponentes struct {
+	versao      versaoSpec
+	raiz        string                // caminho absoluto do documento, usado para reconhecer $refs para ele mesmo
+	componentes map[string]componente // por JSON pointer
+	donos       map[*yaml.Node]string // nó de um componente -> JSON pointer do componente que o contém
+	arestas     map[string][]string   // dono (vazio para a raiz, JSON pointer ou arquivo externo) -> usos
+	usados      map[string]bool
+}
+
+// Função para encontrar os componentes que não são alcançáveis a partir dos paths, webhooks e demais campos
+// do documento; componentes usados apenas por outros componentes não usados também são retornados
+func componentesNaoUsados(rootNode *yaml.Node, idx *index.SpecIndex) []componente {
+	doc := documento(rootNode)
+	versao, _ := detectarVersao(doc)
+	u := &usoComponentes{
+		versao:      versao,
+		componentes: make(map[string]componente),
+		donos:       make(map[*yaml.Node]string),
+		arestas:     make(map[string][]string),
+		usados:      make(map[string]bool),
+	}
+	if idx != nil {
+		u.raiz = idx.GetSpecAbsolutePath()
+	}
+	componentes := listarComponentes(doc)
+	for _, c := range componentes {
+		u.componentes[c.ponteiro] = c
+		u.registrarDono(c.node, c.ponteiro, make(map[*yaml.Node]bool))
+	}
+
+	// as seções de componentes só são percorridas como parte de cada componente
+	secoes := map[string]bool{"components": true}
+	if versao == versaoOAS2 {
+		for _, secao := range secoesComponentesOAS2 {
+			secoes[secao] = true
+		}
+	}
+	// os demais campos da raiz formam o ponto de partida, mantendo as chaves para reconhecer "security"
+	raiz := &yaml.Node{Kind: yaml.MappingNode, Tag: "!!map"}
+	for _, par := range paresDoMapa(doc) {
+		if !secoes[par.chave.Value] {
+			raiz.Content = append(raiz.Content, par.chave, par.valor)
+		}
+	}
+	u.visitar(raiz, "", make(map[*yaml.Node]bool))
+	for _, c := range componentes {
+		u.visitar(c.node, c.ponteiro, make(map[*yaml.Node]bool))
+	}
+	u.adicionarReferencias(idx)
+	u.marcar("")
+
+	var naoUsados []componente
+	for _, c := range componentes {
+		if !u.usados[c.ponteiro] {
+			naoUsados = append(naoUsados, c)
+		}
+	}
+	return naoUsados
+}
+
+// Função para associar cada nó de um componente ao componente, para que $refs e aliases YAML dentro
+// dele sejam atribuídos ao componente
+func (u *usoComponentes) registrarDono(node *yaml.Node, ponteiro string, vistos map[*yaml.Node]bool) {
+	if node == nil || vistos[node] || node.Kind == yaml.AliasNode {
+		return
+	}
+	vistos[node] = true
+	if _, ok := u.donos[node]; !ok {
+		u.donos[node] = ponteiro
+	}
+	for _, filho := range node.Content {
+		u.registrarDono(filho, ponteiro, vistos)
+	}
+}
+
+// Função para adicionar as arestas de $ref registradas pelos índices do documento e dos arquivos
+// referenciados; um arquivo externo é tratado como uma unidade, usada quando qualquer parte dele é usada
+func (u *usoComponentes) adicionarReferencias(idx *index.SpecIndex) {
+	if idx == nil {
+		return
+	}
+	indices := []*index.SpecIndex{idx}
+	if rolodex := idx.GetRolodex(); rolodex != nil {
+		indices = append(indices, rolodex.GetIndexes()...)
+	}
+	vistos := make(map[string]bool)
+	for _, i := range indices {
+		if i == nil {
+			continue
+		}
+		arquivo := i.GetSpecAbsolutePath()
+		if vistos[arquivo] {
+			continue
+		}
+		vistos[arquivo] = true
+		for _, ref := range i.GetRawReferencesSequenced() {
+			dono := arquivo
+			if arquivo == u.raiz {
+				// nós fora dos componentes pertencem à raiz, cujo dono é vazio
+				dono = u.donos[ref.Node]
+			}
+			if destino := u.destino(ref.FullDefinition); destino != "" {
+				u.arestas[dono] = append(u.arestas[dono], destino)
+			}
+		}
+	}
+}
+
+// Função para converter a definição completa de um $ref (arquivo#/ponteiro) no componente ou arquivo usado
+func (u *usoComponentes) destino(definicao string) string {
+	arquivo, fragmento, _ := strings.Cut(definicao, "#")
+	if arquivo != "" && arquivo != u.raiz {
+		return arquivo
+	}
+	return u.componenteDoRef("#" + fragmento)
+}
+
+// Função para percorrer os nós de um dono registrando os usos que não são $refs: componentes usados por
+// mapping de discriminator, por requisito de segurança ou por alias YAML
+func (u *usoComponentes) visitar(node *yaml.Node, dono string, vistos map[*yaml.Node]bool) {
+	if node == nil || vistos[node] {
+		return
+	}
+	if node.Kind == yaml.AliasNode {
+		// o conteúdo do alias pertence a quem o declarou e é percorrido como parte dele
+		if destino, ok := u.donos[node.Alias]; ok {
+			u.arestas[dono] = append(u.arestas[dono], destino)
+		}
+		return
+	}
+	vistos[node] = true
+
+	if node.Kind == yaml.MappingNode {
+		for i := 0; i+1 < len(node.Content); i += 2 {
+			valor := resolverAlias(node.Content[i+1])
+			switch node.Content[i].Value {
+			case "discriminator":
+				for _, par := range paresDoMapa(valorDoMapa(valor, "mapping")) {
+					if ref, ok := texto(par.valor); ok {
+						u.arestas[dono] = append(u.arestas[dono], u.componenteDoRef(u.refDoMapping(ref)))
+					}
+				}
+			case "security":
+				for _, requisito := range itensDaLista(valor) {
+					for _, par := range paresDoMapa(requisito) {
+						u.arestas[dono] = append(u.arestas[dono], u.esquemaDeSeguranca(par.chave.Value))
+					}
+				}
+			}
+		}
+	}
+	for _, filho := range node.Content {
+		u.visitar(filho, dono, vistos)
+	}
+}
+
+// Função para converter um valor do mapping de discriminator em $ref; nomes simples apontam para os schemas
+func (u *usoComponentes) refDoMapping(valor string) string {
+	if strings.Contains(valor, "/") || strings.Contains(valor, "#") {
+		return valor
+	}
+	if u.versao == versaoOAS2 {
+		return "#/definitions/" + escaparPonteiro(valor)
+	}
+	return "#/components/schemas/" + escaparPonteiro(valor)
+}
+
+// Função para obter o JSON pointer do security scheme usado por nome em um requisito de segurança
+func (u *usoComponentes) esquemaDeSeguranca(nome string) string {
+	if u.versao == versaoOAS2 {
+		return "#/securityDefinitions/" + escaparPonteiro(nome)
+	}
+	return "#/components/securitySchemes/" + escaparPonteiro(nome)
+}
+
+// Função para obter o componente apontado por um $ref local; $refs para partes de um componente
+// (#/components/schemas/Pessoa/properties/nome) usam o componente inteiro
+func (u *usoComponentes) componenteDoRef(ref string) string {
+	if !strings.HasPrefix(ref, "#/") {
+		return ""
+	}
+	if decodificado, err := url.PathUnescape(ref); err == nil {
+		ref = decodificado
+	}
+	segmentos := strings.Split(strings.TrimPrefix(ref, "#/"), "/")
+	// components/<seção>/<nome> no OpenAPI 3 e <seção>/<nome> no Swagger 2.0
+	for _, tamanho := range []int{3, 2} {
+		if len(segmentos) >= tamanho {
+			ponteiro := "#/" + strings.Join(segmentos[:tamanho], "/")
+			if _, ok := u.componentes[ponteiro]; ok {
+				return ponteiro
+			}
+		}
+	}
+	return ""
+}
+
+// Função para marcar um dono (a raiz, um componente ou um arquivo externo) como usado e seguir os seus usos
+func (u *usoComponentes) marcar(dono string) {
+	if u.usados[dono] {
+		return
+	}
+	u.usados[dono] = true
+	for _, destino := range u.arestas[dono] {
+		if destino != "" {
+			u.marcar(destino)
+		}
+	}
+}
+
+// Função para remover os componentes não usados do documento, junto com as seções que ficarem vazias
+func removerComponentes(rootNode *yaml.Node, componentes []componente) {
+	for _, c := range componentes {
+		removerChave(c.secao, c.nome)
+	}
+
+	doc := documento(rootNode)
+	if versao, _ := detectarVersao(doc); versao == versaoOAS2 {
+		for _, secao := range secoesComponentesOAS2 {
+			if node := valorDoMapa(doc, secao); node != nil && node.Kind == yaml.MappingNode && len(node.Content) == 0 {
+				removerChave(doc, secao)
+			}
+		}
+		return
+	}
+	components := valorDoMapa(doc, "components")
+	for _, secao := range secoesComponentesOAS3 {
+		if node := valorDoMapa(components, secao); node != nil && node.Kind == yaml.MappingNode && len(node.Content) == 0 {
+			removerChave(components, secao)
+		}
+	}
+	if components != nil && components.Kind == yaml.MappingNode && len(components.Content) == 0 {
+		removerChave(doc, "components")
+	}
+}
+
+// Função para remover os componentes não usados de um arquivo OpenAPI e salvar o resultado; no modo
+// dry-run apenas lista os componentes que seriam removidos
+func pruneOpenAPI(inputFile, outputFile string, dryRun bool, formatacao opcoesSaida, opcoes opcoesReferencias) error {
+	data, err := readFile(inputFile)
+	if err != nil {
+		return err
+	}
+
+	rootNode, formatoEntrada, err := lerDocumento(data)
+	if err != nil {
+		return err
+	}
+	if documento(rootNode) == nil {
+		return fmt.Errorf("o documento %s está vazio ou não é um objeto OpenAPI", inputFile)
+	}
+
+	rolodex, err := novoRolodex(rootNode, inputFile, opcoes)
+	if err != nil {
+		return err
+	}
+	naoUsados := componentesNaoUsados(rootNode, rolodex.GetRootIndex())
+	for _, c := range naoUsados {
+		fmt.Printf("🗑️ %s (linha %d)\n", c.ponteiro, c.node.Line)
+	}
+	if dryRun {
+		fmt.Printf("%d componente(s) não usado(s) em %s\n", len(naoUsados), inputFile)
+		return nil
+	}
+
+	saida, err := escolherFormatoSaida(formatacao.formato, outputFile, formatoEntrada)
+	if err != nil {
+		return err
+	}
+	indentacao, err := escolherIndentacao(formatacao.indentacao, data)
+	if err != nil {
+		return err
+	}
+
+	removerComponentes(rootNode, naoUsados)
+	conteudo, err := codificarDocumento(rootNode, saida, indentacao)
+	if err != nil {
+		return err
+	}
+	if err := ioutil.WriteFile(outputFile, conteudo, 0644); err != nil {
+		return fmt.Errorf("erro ao salvar o arquivo sem os componentes não usados: %v", err)
+	}
+
+	fmt.Printf("✅ %d componente(s) removido(s), arquivo salvo em: %s\n", len(naoUsados), outputFile)
+	return nil
+}
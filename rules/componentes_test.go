@@ -0,0 +1,133 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+// Função para gravar os arquivos de teste e listar os componentes não usados do documento principal
+func naoUsadosDeTeste(t *testing.T, arquivos map[string]string) []string {
+	t.Helper()
+	dir := t.TempDir()
+	for nome, conteudo := range arquivos {
+		if err := os.WriteFile(filepath.Join(dir, nome), []byte(conteudo), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	arquivo := filepath.Join(dir, "openapi.yaml")
+	rootNode, _, err := lerDocumento([]byte(arquivos["openapi.yaml"]))
+	if err != nil {
+		t.Fatal(err)
+	}
+	rolodex, err := novoRolodex(rootNode, arquivo, opcoesReferencias{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	var ponteiros []string
+	for _, c := range componentesNaoUsados(rootNode, rolodex.GetRootIndex()) {
+		ponteiros = append(ponteiros, c.ponteiro)
+	}
+	return ponteiros
+}
+
+func TestComponentesNaoUsados(t *testing.T) {
+	casos := []struct {
+		nome      string
+		arquivos  map[string]string
+		naoUsados []string
+	}{
+		{
+			nome: "usado apenas por componente não usado",
+			arquivos: map[string]string{"openapi.yaml": `
+openapi: 3.0.0
+info: {title: t, version: "1"}
+paths:
+  /a:
+    get:
+      responses:
+        "200": {$ref: '#/components/responses/Ok'}
+components:
+  responses:
+    Ok: {description: ok, content: {application/json: {schema: {$ref: '#/components/schemas/Usado/properties/id'}}}}
+  schemas:
+    Usado: {type: object, properties: {id: {type: string}}}
+    Interno: {type: string}
+    Solto: {type: array, items: {$ref: '#/components/schemas/Interno'}}
+`},
+			naoUsados: []string{"#/components/schemas/Interno", "#/components/schemas/Solto"},
+		},
+		{
+			nome: "alias, discriminator e security",
+			arquivos: map[string]string{"openapi.yaml": `
+openapi: 3.0.0
+info: {title: t, version: "1"}
+security: [{Chave: []}]
+components:
+  securitySchemes:
+    Chave: {type: apiKey, in: header, name: x-api-key}
+    Outra: {type: apiKey, in: header, name: x-outra}
+  schemas:
+    Base: &base {type: object}
+    Pix: {type: object}
+    Ted: {type: object}
+paths:
+  /a:
+    post:
+      requestBody:
+        content:
+          application/json:
+            schema: *base
+      responses:
+        "200":
+          description: ok
+          content:
+            application/json:
+              schema:
+                oneOf: [{$ref: '#/components/schemas/Pix'}]
+                discriminator: {propertyName: tipo, mapping: {ted: Ted}}
+`},
+			naoUsados: []string{"#/components/securitySchemes/Outra"},
+		},
+		{
+			nome: "arquivo externo que usa componentes do documento",
+			arquivos: map[string]string{
+				"openapi.yaml": `
+openapi: 3.0.0
+info: {title: t, version: "1"}
+paths:
+  /a:
+    get:
+      responses:
+        "200":
+          description: ok
+          content:
+            application/json:
+              schema: {$ref: './comum.yaml#/components/schemas/Externo'}
+components:
+  schemas:
+    ViaExterno: {type: string}
+    Solto: {type: string}
+`,
+				"comum.yaml": `
+components:
+  schemas:
+    Externo:
+      type: object
+      properties:
+        x: {$ref: './openapi.yaml#/components/schemas/ViaExterno'}
+`,
+			},
+			naoUsados: []string{"#/components/schemas/Solto"},
+		},
+	}
+
+	for _, c := range casos {
+		t.Run(c.nome, func(t *testing.T) {
+			if got := naoUsadosDeTeste(t, c.arquivos); !reflect.DeepEqual(got, c.naoUsados) {
+				t.Errorf("componentes não usados = %v, esperado %v", got, c.naoUsados)
+			}
+		})
+	}
+}
@@ -0,0 +1,729 @@
+package main
+
+import (
+	"fmt"
+	"io/ioutil"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// versão do OpenAPI gerada pelo comando convert
+const versaoConvertida = "3.0.3"
+
+// campos de um parâmetro ou header Swagger 2.0 que passam para o "schema" no OpenAPI 3.0
+var camposDeSchema = map[string]bool{
+	"type": true, "format": true, "items": true, "default": true, "enum": true, "multipleOf": true,
+	"maximum": true, "exclusiveMaximum": true, "minimum": true, "exclusiveMinimum": true,
+	"maxLength": true, "minLength": true, "pattern": true, "maxItems": true, "minItems": true, "uniqueItems": true,
+}
+
+// fluxos OAuth2 do Swagger 2.0 e os nomes equivalentes no OpenAPI 3.0
+var fluxosOAuth2 = map[string]string{
+	"implicit":    "implicit",
+	"password":    "password",
+	"application": "clientCredentials",
+	"accessCode":  "authorizationCode",
+}
+
+// conversor transforma um documento Swagger 2.0 em OpenAPI 3.0, acumulando como findings
+// tudo o que não pôde ser convertido
+type conversor struct {
+	doc      *yaml.Node
+	consumes []string
+	produces []string
+	avisos   []error
+}
+
+// Função para converter um documento Swagger 2.0 em OpenAPI 3.0.x
+func converterSwagger2(rootNode *yaml.Node) (*yaml.Node, []error, error) {
+	doc := documento(rootNode)
+	if versao, valor := detectarVersao(doc); versao != versaoOAS2 {
+		return nil, nil, fmt.Errorf("o documento não é Swagger 2.0 (versão %q)", valor)
+	}
+
+	c := &conversor{
+		doc:      doc,
+		consumes: textosDaLista(valorDoMapa(doc, "consumes")),
+		produces: textosDaLista(valorDoMapa(doc, "produces")),
+	}
+	saida := novoMapa()
+	for _, par := range paresDoMapa(doc) {
+		switch par.chave.Value {
+		case "swagger":
+			definirValor(saida, "openapi", novoTexto(versaoConvertida))
+		case "host", "basePath", "schemes":
+			if !temChave(saida, "servers") {
+				definirValor(saida, "servers", c.servers())
+			}
+		case "consumes", "produces":
+			// viram os mapas "content" dos request bodies e responses
+		case "definitions", "parameters", "responses", "securityDefinitions":
+			if !temChave(saida, "components") {
+				definirValor(saida, "components", c.components())
+			}
+		case "paths":
+			definirValor(saida, "paths", c.paths(par.valor))
+		default:
+			definirValor(saida, par.chave.Value, par.valor)
+		}
+	}
+	c.reescreverRefs(saida, make(map[*yaml.Node]bool))
+
+	localizarFindings(rootNode, c.avisos)
+	return &yaml.Node{Kind: yaml.DocumentNode, Content: []*yaml.Node{saida}}, c.avisos, nil
+}
+
+// Função para registrar algo que não pôde ser convertido
+func (c *conversor) avisar(campo, descricao string, args ...interface{}) {
+	c.avisos = append(c.avisos, &finding{
+		regra:      "convert",
+		severidade: "warn",
+		campo:      campo,
+		descricao:  fmt.Sprintf(descricao, args...),
+	})
+}
+
+// Função para montar a lista "servers" a partir de host, basePath e schemes
+func (c *conversor) servers() *yaml.Node {
+	host, _ := texto(valorDoMapa(c.doc, "host"))
+	basePath, _ := texto(valorDoMapa(c.doc, "basePath"))
+	if basePath == "/" {
+		basePath = ""
+	}
+	if host == "" {
+		if basePath == "" {
+			basePath = "/"
+		}
+		return novaLista(c.server(basePath))
+	}
+
+	schemes := textosDaLista(valorDoMapa(c.doc, "schemes"))
+	if len(schemes) == 0 {
+		c.avisar("#/host", "O documento não declara `schemes`; foi usado https na URL do servidor")
+		schemes = []string{"https"}
+	}
+	servers := novaLista()
+	for _, scheme := range schemes {
+		servers.Content = append(servers.Content, c.server(scheme+"://"+host+basePath))
+	}
+	return servers
+}
+
+func (c *conversor) server(url string) *yaml.Node {
+	server := novoMapa()
+	definirValor(server, "url", novoTexto(url))
+	return server
+}
+
+// Função para montar "components" a partir de definitions, parameters, responses e securityDefinitions
+func (c *conversor) components() *yaml.Node {
+	components := novoMapa()
+
+	if definitions := valorDoMapa(c.doc, "definitions"); definitions != nil {
+		schemas := novoMapa()
+		for _, par := range paresDoMapa(definitions) {
+			definirValor(schemas, par.chave.Value, c.schema(par.valor, make(map[*yaml.Node]bool)))
+		}
+		definirValor(components, "schemas", schemas)
+	}
+
+	parametros, requestBodies := novoMapa(), novoMapa()
+	for _, par := range paresDoMapa(valorDoMapa(c.doc, "parameters")) {
+		ponteiro := "#/parameters/" + escaparPonteiro(par.chave.Value)
+		switch local, _ := texto(valorDoMapa(par.valor, "in")); local {
+		case "body":
+			definirValor(requestBodies, par.chave.Value, c.requestBody(par.valor, c.consumes))
+		case "formData":
+			// parâmetros de formulário não existem isolados no OpenAPI 3.0; são incorporados em cada operação que os referencia
+		default:
+			definirValor(parametros, par.chave.Value, c.parametro(par.valor, ponteiro))
+		}
+	}
+	if len(parametros.Content) > 0 {
+		definirValor(components, "parameters", parametros)
+	}
+	if len(requestBodies.Content) > 0 {
+		definirValor(components, "requestBodies", requestBodies)
+	}
+
+	if responses := valorDoMapa(c.doc, "responses"); responses != nil {
+		convertidas := novoMapa()
+		for _, par := range paresDoMapa(responses) {
+			convertidas.Content = append(convertidas.Content, par.chave, c.response(par.valor, c.produces, "#/responses/"+escaparPonteiro(par.chave.Value)))
+		}
+		definirValor(components, "responses", convertidas)
+	}
+
+	if definicoes := valorDoMapa(c.doc, "securityDefinitions"); definicoes != nil {
+		schemes := novoMapa()
+		for _, par := range paresDoMapa(definicoes) {
+			ponteiro := "#/securityDefinitions/" + escaparPonteiro(par.chave.Value)
+			if scheme := c.securityScheme(par.valor, ponteiro); scheme != nil {
+				definirValor(schemes, par.chave.Value, scheme)
+			}
+		}
+		definirValor(components, "securitySchemes", schemes)
+	}
+	return components
+}
+
+// Função para converter um security scheme: basic vira http, e os fluxos OAuth2 passam para "flows"
+func (c *conversor) securityScheme(definicao *yaml.Node, ponteiro string) *yaml.Node {
+	tipo, _ := texto(valorDoMapa(definicao, "type"))
+	scheme := novoMapa()
+	switch tipo {
+	case "basic":
+		definirValor(scheme, "type", novoTexto("http"))
+		definirValor(scheme, "scheme", novoTexto("basic"))
+	case "apiKey":
+		definirValor(scheme, "type", novoTexto("apiKey"))
+		definirValor(scheme, "name", novoTexto(valorTexto(definicao, "name")))
+		definirValor(scheme, "in", novoTexto(valorTexto(definicao, "in")))
+	case "oauth2":
+		fluxo, _ := texto(valorDoMapa(definicao, "flow"))
+		nome, ok := fluxosOAuth2[fluxo]
+		if !ok {
+			c.avisar(ponteiro+"/flow", "Fluxo OAuth2 %q desconhecido; o security scheme não foi convertido", fluxo)
+			return nil
+		}
+		flow := novoMapa()
+		for _, campo := range []string{"authorizationUrl", "tokenUrl"} {
+			if valor := valorDoMapa(definicao, campo); valor != nil {
+				definirValor(flow, campo, valor)
+			}
+		}
+		scopes := valorDoMapa(definicao, "scopes")
+		if scopes == nil {
+			scopes = novoMapa()
+		}
+		definirValor(flow, "scopes", scopes)
+		flows := novoMapa()
+		definirValor(flows, nome, flow)
+		definirValor(scheme, "type", novoTexto("oauth2"))
+		definirValor(scheme, "flows", flows)
+	default:
+		c.avisar(ponteiro+"/type", "Tipo de security scheme %q desconhecido; o security scheme não foi convertido", tipo)
+		return nil
+	}
+
+	for _, par := range paresDoMapa(definicao) {
+		if par.chave.Value == "description" || strings.HasPrefix(par.chave.Value, "x-") {
+			definirValor(scheme, par.chave.Value, par.valor)
+		}
+	}
+	return scheme
+}
+
+// Função para converter os path items, movendo parâmetros body/formData de cada operação para requestBody
+func (c *conversor) paths(paths *yaml.Node) *yaml.Node {
+	convertidos := novoMapa()
+	for _, par := range paresDoMapa(paths) {
+		ponteiro := "#/paths/" + escaparPonteiro(par.chave.Value)
+		if strings.HasPrefix(par.chave.Value, "x-") {
+			convertidos.Content = append(convertidos.Content, par.chave, par.valor)
+			continue
+		}
+
+		// parâmetros body/formData do path item são repassados para as operações, que é onde fica o requestBody
+		var parametrosPath, corposPath []*yaml.Node
+		for i, param := range itensDaLista(valorDoMapa(par.valor, "parameters")) {
+			if c.emCorpo(param) {
+				corposPath = append(corposPath, param)
+			} else {
+				parametrosPath = append(parametrosPath, c.parametro(param, fmt.Sprintf("%s/parameters/%d", ponteiro, i)))
+			}
+		}
+
+		item := novoMapa()
+		for _, campo := range paresDoMapa(par.valor) {
+			switch {
+			case campo.chave.Value == "parameters":
+				if len(parametrosPath) > 0 {
+					definirValor(item, "parameters", novaLista(parametrosPath...))
+				}
+			case contemTexto(metodosHTTP, campo.chave.Value):
+				definirValor(item, campo.chave.Value, c.operacao(campo.valor, ponteiro+"/"+campo.chave.Value, corposPath))
+			default:
+				definirValor(item, campo.chave.Value, campo.valor)
+			}
+		}
+		convertidos.Content = append(convertidos.Content, par.chave, item)
+	}
+	return convertidos
+}
+
+// Função para converter uma operação
+func (c *conversor) operacao(op *yaml.Node, ponteiro string, corposPath []*yaml.Node) *yaml.Node {
+	consumes, produces := c.consumes, c.produces
+	if valor := valorDoMapa(op, "consumes"); valor != nil {
+		consumes = textosDaLista(valor)
+	}
+	if valor := valorDoMapa(op, "produces"); valor != nil {
+		produces = textosDaLista(valor)
+	}
+
+	var parametros, formulario []*yaml.Node
+	var requestBody *yaml.Node
+	params := itensDaLista(valorDoMapa(op, "parameters"))
+	for i, param := range params {
+		campo := fmt.Sprintf("%s/parameters/%d", ponteiro, i)
+		local, _ := texto(valorDoMapa(c.resolverParametro(param), "in"))
+		switch {
+		case local == "body" && requestBody != nil:
+			c.avisar(campo, "A operação possui mais de um parâmetro body; apenas o primeiro foi convertido")
+		case local == "body":
+			requestBody = c.requestBody(param, consumes)
+		case local == "formData":
+			formulario = append(formulario, c.resolverParametro(param))
+		default:
+			parametros = append(parametros, c.parametro(param, campo))
+		}
+	}
+	for _, param := range corposPath {
+		param = c.resolverParametro(param)
+		if local, _ := texto(valorDoMapa(param, "in")); local == "body" {
+			if requestBody == nil {
+				requestBody = c.requestBody(param, consumes)
+			}
+		} else if !c.parametroDeclarado(params, param) {
+			formulario = append(formulario, param)
+		}
+	}
+	if len(formulario) > 0 {
+		if requestBody != nil {
+			c.avisar(ponteiro, "A operação possui parâmetros body e formData; os parâmetros formData foram descartados")
+		} else {
+			requestBody = c.formulario(formulario, consumes)
+		}
+	}
+
+	convertida := novoMapa()
+	for _, par := range paresDoMapa(op) {
+		switch par.chave.Value {
+		case "consumes", "produces":
+		case "schemes":
+			c.avisar(ponteiro+"/schemes", "O OpenAPI 3.0 não possui `schemes` por operação; declare um `servers` na operação se necessário")
+		case "parameters":
+			if len(parametros) > 0 {
+				definirValor(convertida, "parameters", novaLista(parametros...))
+			}
+			if requestBody != nil {
+				definirValor(convertida, "requestBody", requestBody)
+			}
+		case "responses":
+			responses := novoMapa()
+			for _, resp := range paresDoMapa(par.valor) {
+				campo := ponteiro + "/responses/" + escaparPonteiro(resp.chave.Value)
+				responses.Content = append(responses.Content, resp.chave, c.response(resp.valor, produces, campo))
+			}
+			definirValor(convertida, "responses", responses)
+		default:
+			definirValor(convertida, par.chave.Value, par.valor)
+		}
+	}
+	if requestBody != nil && !temChave(convertida, "requestBody") {
+		definirValor(convertida, "requestBody", requestBody)
+	}
+	return convertida
+}
+
+// Função para obter a definição de um parâmetro, seguindo o $ref para #/parameters
+func (c *conversor) resolverParametro(param *yaml.Node) *yaml.Node {
+	ref, ok := texto(valorDoMapa(param, "$ref"))
+	if !ok || !strings.HasPrefix(ref, "#/parameters/") {
+		return param
+	}
+	nome := strings.TrimPrefix(ref, "#/parameters/")
+	nome = strings.ReplaceAll(strings.ReplaceAll(nome, "~1", "/"), "~0", "~")
+	if definicao := valorDoMapa(valorDoMapa(c.doc, "parameters"), nome); definicao != nil {
+		return definicao
+	}
+	return param
+}
+
+// Função para verificar se um parâmetro vai para o requestBody (in: body ou in: formData)
+func (c *conversor) emCorpo(param *yaml.Node) bool {
+	local, _ := texto(valorDoMapa(c.resolverParametro(param), "in"))
+	return local == "body" || local == "formData"
+}
+
+// Função para verificar se a operação já declara um parâmetro com o mesmo nome e localização,
+// caso em que ele sobrescreve o parâmetro do path item
+func (c *conversor) parametroDeclarado(params []*yaml.Node, param *yaml.Node) bool {
+	nome, local := valorTexto(param, "name"), valorTexto(param, "in")
+	for _, declarado := range params {
+		declarado = c.resolverParametro(declarado)
+		if valorTexto(declarado, "name") == nome && valorTexto(declarado, "in") == local {
+			return true
+		}
+	}
+	return false
+}
+
+// Função para converter um parâmetro que não é body/formData, movendo os campos de tipo para "schema"
+func (c *conversor) parametro(param *yaml.Node, ponteiro string) *yaml.Node {
+	if ref, ok := texto(valorDoMapa(param, "$ref")); ok {
+		return c.referencia(ref)
+	}
+
+	convertido := novoMapa()
+	for _, par := range paresDoMapa(param) {
+		if camposDeSchema[par.chave.Value] {
+			continue
+		}
+		if par.chave.Value == "collectionFormat" {
+			c.estilo(convertido, param, ponteiro)
+			continue
+		}
+		definirValor(convertido, par.chave.Value, par.valor)
+	}
+	definirValor(convertido, "schema", c.schemaDeCampos(param, ponteiro))
+	return convertido
+}
+
+// Função para converter o collectionFormat de um parâmetro do tipo array em style/explode
+func (c *conversor) estilo(convertido, param *yaml.Node, ponteiro string) {
+	formato, _ := texto(valorDoMapa(param, "collectionFormat"))
+	local, _ := texto(valorDoMapa(param, "in"))
+	consulta := local == "query" || local == "formData"
+	switch {
+	case formato == "csv" && consulta:
+		definirValor(convertido, "explode", novoBooleano(false))
+	case formato == "csv":
+		// style simple sem explode é o padrão para path e header
+	case formato == "multi" && consulta:
+		// style form com explode é o padrão para query
+	case formato == "ssv" && consulta:
+		definirValor(convertido, "style", novoTexto("spaceDelimited"))
+		definirValor(convertido, "explode", novoBooleano(false))
+	case formato == "pipes" && consulta:
+		definirValor(convertido, "style", novoTexto("pipeDelimited"))
+		definirValor(convertido, "explode", novoBooleano(false))
+	default:
+		c.avisar(ponteiro+"/collectionFormat", "collectionFormat %q em parâmetro %q não tem equivalente no OpenAPI 3.0", formato, local)
+	}
+}
+
+// Função para montar um schema a partir dos campos de tipo de um parâmetro, header ou items do Swagger 2.0
+func (c *conversor) schemaDeCampos(node *yaml.Node, ponteiro string) *yaml.Node {
+	schema := novoMapa()
+	for _, par := range paresDoMapa(node) {
+		switch {
+		case par.chave.Value == "type" && par.valor.Value == "file":
+			definirValor(schema, "type", novoTexto("string"))
+			definirValor(schema, "format", novoTexto("binary"))
+		case par.chave.Value == "format" && temChave(schema, "format"):
+		case par.chave.Value == "items":
+			definirValor(schema, "items", c.schemaDeCampos(par.valor, ponteiro+"/items"))
+			if temChave(par.valor, "collectionFormat") {
+				c.avisar(ponteiro+"/items/collectionFormat", "collectionFormat em arrays aninhados não tem equivalente no OpenAPI 3.0")
+			}
+		case camposDeSchema[par.chave.Value]:
+			definirValor(schema, par.chave.Value, par.valor)
+		}
+	}
+	return schema
+}
+
+// Função para converter um parâmetro body em requestBody, com um media type para cada consumes
+func (c *conversor) requestBody(param *yaml.Node, consumes []string) *yaml.Node {
+	if ref, ok := texto(valorDoMapa(param, "$ref")); ok {
+		body := novoMapa()
+		definirValor(body, "$ref", novoTexto(strings.Replace(ref, "#/parameters/", "#/components/requestBodies/", 1)))
+		return body
+	}
+
+	body := novoMapa()
+	if descricao := valorDoMapa(param, "description"); descricao != nil {
+		definirValor(body, "description", descricao)
+	}
+	schema := c.schema(valorDoMapa(param, "schema"), make(map[*yaml.Node]bool))
+	if schema == nil {
+		schema = novoMapa()
+	}
+	definirValor(body, "content", c.conteudo(consumes, schema, nil))
+	if obrigatorio, ok := booleano(valorDoMapa(param, "required")); ok && obrigatorio {
+		definirValor(body, "required", novoBooleano(true))
+	}
+	for _, par := range paresDoMapa(param) {
+		if strings.HasPrefix(par.chave.Value, "x-") {
+			definirValor(body, par.chave.Value, par.valor)
+		}
+	}
+	return body
+}
+
+// Função para converter os parâmetros formData em um requestBody com um schema do tipo object
+func (c *conversor) formulario(params []*yaml.Node, consumes []string) *yaml.Node {
+	propriedades := novoMapa()
+	var obrigatorios []*yaml.Node
+	arquivo := false
+	for _, param := range params {
+		nome := valorTexto(param, "name")
+		if tipo, _ := texto(valorDoMapa(param, "type")); tipo == "file" {
+			arquivo = true
+		}
+		propriedade := c.schemaDeCampos(param, "")
+		if descricao := valorDoMapa(param, "description"); descricao != nil {
+			definirValor(propriedade, "description", descricao)
+		}
+		definirValor(propriedades, nome, propriedade)
+		if obrigatorio, ok := booleano(valorDoMapa(param, "required")); ok && obrigatorio {
+			obrigatorios = append(obrigatorios, novoTexto(nome))
+		}
+	}
+
+	schema := novoMapa()
+	definirValor(schema, "type", novoTexto("object"))
+	definirValor(schema, "properties", propriedades)
+	if len(obrigatorios) > 0 {
+		definirValor(schema, "required", novaLista(obrigatorios...))
+	}
+
+	// usa os media types de formulário declarados em consumes; sem nenhum, escolhe pelo tipo dos campos
+	var mediaTypes []string
+	for _, mediaType := range consumes {
+		if mediaType == "multipart/form-data" || mediaType == "application/x-www-form-urlencoded" {
+			mediaTypes = append(mediaTypes, mediaType)
+		}
+	}
+	if len(mediaTypes) == 0 {
+		mediaTypes = []string{"application/x-www-form-urlencoded"}
+		if arquivo {
+			mediaTypes = []string{"multipart/form-data"}
+		}
+	}
+
+	body := novoMapa()
+	definirValor(body, "content", c.conteudo(mediaTypes, schema, nil))
+	if len(obrigatorios) > 0 {
+		definirValor(body, "required", novoBooleano(true))
+	}
+	return body
+}
+
+// Função para converter uma response: schema e examples passam para "content", e os headers ganham "schema"
+func (c *conversor) response(resp *yaml.Node, produces []string, ponteiro string) *yaml.Node {
+	if ref, ok := texto(valorDoMapa(resp, "$ref")); ok {
+		return c.referencia(ref)
+	}
+
+	convertida := novoMapa()
+	schema := c.schema(valorDoMapa(resp, "schema"), make(map[*yaml.Node]bool))
+	examples := valorDoMapa(resp, "examples")
+	for _, par := range paresDoMapa(resp) {
+		switch par.chave.Value {
+		case "schema", "examples":
+			if !temChave(convertida, "content") && (schema != nil || examples != nil) {
+				definirValor(convertida, "content", c.conteudo(produces, schema, examples))
+			}
+		case "headers":
+			headers := novoMapa()
+			for _, header := range paresDoMapa(par.valor) {
+				campo := ponteiro + "/headers/" + escaparPonteiro(header.chave.Value)
+				headers.Content = append(headers.Content, header.chave, c.header(header.valor, campo))
+			}
+			definirValor(convertida, "headers", headers)
+		default:
+			definirValor(convertida, par.chave.Value, par.valor)
+		}
+	}
+	return convertida
+}
+
+// Função para converter um header, movendo os campos de tipo para "schema"
+func (c *conversor) header(header *yaml.Node, ponteiro string) *yaml.Node {
+	convertido := novoMapa()
+	for _, par := range paresDoMapa(header) {
+		switch {
+		case camposDeSchema[par.chave.Value]:
+		case par.chave.Value == "collectionFormat":
+			if formato, _ := texto(par.valor); formato != "csv" {
+				c.avisar(ponteiro+"/collectionFormat", "collectionFormat %q em header não tem equivalente no OpenAPI 3.0", formato)
+			}
+		default:
+			definirValor(convertido, par.chave.Value, par.valor)
+		}
+	}
+	definirValor(convertido, "schema", c.schemaDeCampos(header, ponteiro))
+	return convertido
+}
+
+// Função para montar o mapa "content" com um media type para cada item da lista (application/json por padrão)
+func (c *conversor) conteudo(mediaTypes []string, schema, examples *yaml.Node) *yaml.Node {
+	if len(mediaTypes) == 0 {
+		mediaTypes = []string{"application/json"}
+	}
+	// exemplos de media types que não estão em produces também geram uma entrada em content
+	for _, par := range paresDoMapa(examples) {
+		if !contemTexto(mediaTypes, par.chave.Value) {
+			mediaTypes = append(mediaTypes, par.chave.Value)
+		}
+	}
+
+	content := novoMapa()
+	for _, mediaType := range mediaTypes {
+		media := novoMapa()
+		if schema != nil {
+			definirValor(media, "schema", schema)
+		}
+		if exemplo := valorDoMapa(examples, mediaType); exemplo != nil {
+			definirValor(media, "example", exemplo)
+		}
+		definirValor(content, mediaType, media)
+	}
+	return content
+}
+
+// Função para converter as diferenças de um schema do Swagger 2.0: discriminator como texto, x-nullable e type file
+func (c *conversor) schema(schema *yaml.Node, vistos map[*yaml.Node]bool) *yaml.Node {
+	schema = resolverAlias(schema)
+	if schema == nil || schema.Kind != yaml.MappingNode || vistos[schema] {
+		return schema
+	}
+	vistos[schema] = true
+
+	for i := 0; i+1 < len(schema.Content); i += 2 {
+		chave, valor := schema.Content[i], resolverAlias(schema.Content[i+1])
+		if valor == nil {
+			continue
+		}
+		switch chave.Value {
+		case "discriminator":
+			if valor.Kind == yaml.ScalarNode {
+				discriminator := novoMapa()
+				definirValor(discriminator, "propertyName", valor)
+				schema.Content[i+1] = discriminator
+			}
+		case "x-nullable":
+			chave.Value = "nullable"
+		case "type":
+			if valor.Value == "file" {
+				schema.Content[i+1] = novoTexto("string")
+				definirValor(schema, "format", novoTexto("binary"))
+			}
+		case "properties", "definitions":
+			for _, par := range paresDoMapa(valor) {
+				c.schema(par.valor, vistos)
+			}
+		case "allOf", "anyOf", "oneOf":
+			for _, item := range itensDaLista(valor) {
+				c.schema(item, vistos)
+			}
+		case "items", "additionalProperties", "not":
+			c.schema(valor, vistos)
+		}
+	}
+	return schema
+}
+
+// Função para criar um objeto de referência
+func (c *conversor) referencia(ref string) *yaml.Node {
+	node := novoMapa()
+	definirValor(node, "$ref", novoTexto(ref))
+	return node
+}
+
+// prefixos de $ref do Swagger 2.0 e os equivalentes no OpenAPI 3.0
+var prefixosRef = []struct{ antigo, novo string }{
+	{"#/definitions/", "#/components/schemas/"},
+	{"#/parameters/", "#/components/parameters/"},
+	{"#/responses/", "#/components/responses/"},
+}
+
+// Função para reescrever os $refs do documento convertido para a estrutura de components
+func (c *conversor) reescreverRefs(node *yaml.Node, vistos map[*yaml.Node]bool) {
+	node = resolverAlias(node)
+	if node == nil || vistos[node] {
+		return
+	}
+	vistos[node] = true
+
+	switch node.Kind {
+	case yaml.MappingNode:
+		for i := 0; i+1 < len(node.Content); i += 2 {
+			valor := resolverAlias(node.Content[i+1])
+			if node.Content[i].Value == "$ref" && valor != nil && valor.Kind == yaml.ScalarNode {
+				node.Content[i+1] = novoTexto(c.novaRef(valor.Value))
+				continue
+			}
+			c.reescreverRefs(valor, vistos)
+		}
+	case yaml.SequenceNode:
+		for _, item := range node.Content {
+			c.reescreverRefs(item, vistos)
+		}
+	}
+}
+
+// Função para converter um $ref, mantendo o arquivo das referências externas
+func (c *conversor) novaRef(ref string) string {
+	arquivo, fragmento := "", ref
+	if i := strings.Index(ref, "#"); i > 0 {
+		arquivo, fragmento = ref[:i], ref[i:]
+	}
+	for _, prefixo := range prefixosRef {
+		if strings.HasPrefix(fragmento, prefixo.antigo) {
+			novo := prefixo.novo + strings.TrimPrefix(fragmento, prefixo.antigo)
+			if arquivo != "" {
+				c.avisar("", "A referência externa %q foi reescrita para %q; o arquivo %s também precisa ser convertido", ref, arquivo+novo, arquivo)
+			}
+			return arquivo + novo
+		}
+	}
+	return ref
+}
+
+// Função para ler um campo texto de um mapping, retornando vazio quando ausente
+func valorTexto(node *yaml.Node, chave string) string {
+	valor, _ := texto(valorDoMapa(node, chave))
+	return valor
+}
+
+// Função para verificar se uma lista de textos contém o valor informado
+func contemTexto(lista []string, valor string) bool {
+	for _, item := range lista {
+		if item == valor {
+			return true
+		}
+	}
+	return false
+}
+
+// Função para converter um arquivo Swagger 2.0 em OpenAPI 3.0 e salvar em YAML ou JSON
+func convertOpenAPI(inputFile, outputFile string, formatacao opcoesSaida) ([]error, error) {
+	data, err := readFile(inputFile)
+	if err != nil {
+		return nil, err
+	}
+
+	rootNode, formatoEntrada, err := lerDocumento(data)
+	if err != nil {
+		return nil, err
+	}
+
+	saida, err := escolherFormatoSaida(formatacao.formato, outputFile, formatoEntrada)
+	if err != nil {
+		return nil, err
+	}
+	indentacao, err := escolherIndentacao(formatacao.indentacao, data)
+	if err != nil {
+		return nil, err
+	}
+
+	convertido, avisos, err := converterSwagger2(rootNode)
+	if err != nil {
+		return nil, err
+	}
+
+	conteudo, err := codificarDocumento(convertido, saida, indentacao)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := ioutil.WriteFile(outputFile, conteudo, 0644); err != nil {
+		return nil, fmt.Errorf("erro ao salvar arquivo convertido: %v", err)
+	}
+	return avisos, nil
+}
@@ -0,0 +1,224 @@
+package main
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"sort"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// semelhanca classifica um grupo de schemas duplicados, do mais forte para o mais fraco
+type semelhanca int
+
+const (
+	identicos   semelhanca = iota
+	estruturais            // diferem apenas na documentação (descrições, exemplos, x-*)
+	semelhantes            // têm as mesmas propriedades e tipos, e diferem em restrições ou em required
+)
+
+// grupoDuplicado reúne os schemas com o mesmo conteúdo normalizado
+type grupoDuplicado struct {
+	ponteiros  []string
+	semelhanca semelhanca
+}
+
+// Função para listar os candidatos a duplicados: os schemas de componentes e, dentro deles, os sub-schemas de
+// objeto com pelo menos minimoPropriedades propriedades; schemas que são apenas um $ref não entram, pois já
+// reutilizam outro schema
+func candidatosADuplicados(schemas []localSchema, minimoPropriedades int) []localSchema {
+	var candidatos []localSchema
+	for _, schema := range schemas {
+		if !strings.HasPrefix(schema.ponteiro, "#/components/schemas/") && !strings.HasPrefix(schema.ponteiro, "#/definitions/") {
+			continue
+		}
+		percorrerSubschemas(schema.node, schema.ponteiro, func(node *yaml.Node, ponteiro string) {
+			if pares := paresDoMapa(node); len(pares) == 1 && pares[0].chave.Value == "$ref" {
+				return
+			}
+			if ponteiro != schema.ponteiro && len(paresDoMapa(valorDoMapa(node, "properties"))) < minimoPropriedades {
+				return
+			}
+			candidatos = append(candidatos, localSchema{ponteiro: ponteiro, node: node})
+		})
+	}
+	return candidatos
+}
+
+// Função para verificar se algum ancestral do ponteiro está no conjunto
+func ancestralAgrupado(ponteiro string, agrupados map[string]bool) bool {
+	for i := strings.LastIndex(ponteiro, "/"); i > 0; i = strings.LastIndex(ponteiro[:i], "/") {
+		if agrupados[ponteiro[:i]] {
+			return true
+		}
+	}
+	return false
+}
+
+// Função para agrupar os schemas idênticos e, entre os demais, os que têm a mesma estrutura e os objetos que
+// têm as mesmas propriedades e tipos; o primeiro schema de cada grupo representa o grupo nos níveis seguintes,
+// e sub-schemas de um schema já agrupado não são repetidos nos grupos
+func agruparSchemasDuplicados(schemas []localSchema, minimoPropriedades int) []grupoDuplicado {
+	candidatos := candidatosADuplicados(schemas, minimoPropriedades)
+
+	var grupos []grupoDuplicado
+	agrupados := make(map[string]bool)
+	representados := make(map[string]bool)
+	agrupar := func(hash func(node *yaml.Node) string, nivel semelhanca) {
+		porHash := make(map[string][]string)
+		var ordem []string
+		for _, schema := range candidatos {
+			if representados[schema.ponteiro] {
+				continue
+			}
+			// propriedades e tipos iguais só são significativos em objetos com várias propriedades
+			if nivel == semelhantes && len(paresDoMapa(valorDoMapa(schema.node, "properties"))) < minimoPropriedades {
+				continue
+			}
+			h := hash(schema.node)
+			if _, ok := porHash[h]; !ok {
+				ordem = append(ordem, h)
+			}
+			porHash[h] = append(porHash[h], schema.ponteiro)
+		}
+		for _, h := range ordem {
+			if len(porHash[h]) < 2 {
+				continue
+			}
+			grupos = append(grupos, grupoDuplicado{ponteiros: porHash[h], semelhanca: nivel})
+			for i, ponteiro := range porHash[h] {
+				agrupados[ponteiro] = true
+				representados[ponteiro] = i > 0
+			}
+		}
+	}
+	agrupar(hashDoNode, identicos)
+	agrupar(func(node *yaml.Node) string { return hashEstrutural(node, estruturais) }, estruturais)
+	agrupar(func(node *yaml.Node) string { return hashEstrutural(node, semelhantes) }, semelhantes)
+
+	var resultado []grupoDuplicado
+	for _, grupo := range grupos {
+		var ponteiros []string
+		for _, ponteiro := range grupo.ponteiros {
+			if !ancestralAgrupado(ponteiro, agrupados) {
+				ponteiros = append(ponteiros, ponteiro)
+			}
+		}
+		if len(ponteiros) >= 2 {
+			resultado = append(resultado, grupoDuplicado{ponteiros: ponteiros, semelhanca: grupo.semelhanca})
+		}
+	}
+	return resultado
+}
+
+// Função para validar se há schemas duplicados, com um finding por grupo no primeiro schema
+func validarSchemasDuplicados(schemas []localSchema, validationErrors *[]error, r regra) []error {
+	minimoPropriedades, ok := r.opcoes()["minProperties"].(int)
+	if !ok {
+		minimoPropriedades = 2
+	}
+	for _, grupo := range agruparSchemasDuplicados(schemas, minimoPropriedades) {
+		lista, primeiro := strings.Join(grupo.ponteiros, ", "), grupo.ponteiros[0]
+		var detalhe string
+		switch grupo.semelhanca {
+		case identicos:
+			detalhe = fmt.Sprintf("Os schemas %s são idênticos; considere manter apenas %s e referenciá-lo com $ref.", lista, primeiro)
+		case estruturais:
+			detalhe = fmt.Sprintf("Os schemas %s têm a mesma estrutura e diferem apenas em descrições e exemplos; considere manter apenas %s e referenciá-lo com $ref.", lista, primeiro)
+		case semelhantes:
+			detalhe = fmt.Sprintf("Os schemas %s têm as mesmas propriedades e tipos e diferem apenas em restrições ou em required; considere um schema comum, ajustado com allOf onde necessário.", lista)
+		}
+		*validationErrors = append(*validationErrors, r.findingDetalhado(primeiro, detalhe))
+	}
+	return *validationErrors
+}
+
+// camposDeDocumentacao não alteram a estrutura de um schema e são ignorados por hashEstrutural,
+// assim como as extensões x-*
+var camposDeDocumentacao = map[string]bool{
+	"description":  true,
+	"title":        true,
+	"summary":      true,
+	"example":      true,
+	"examples":     true,
+	"externalDocs": true,
+}
+
+// camposDeNomes são os campos de um schema cujas chaves são nomes escolhidos pelo autor, e não palavras-chave
+var camposDeNomes = map[string]bool{"properties": true, "patternProperties": true, "definitions": true, "$defs": true}
+
+// camposDeTipos são os únicos campos considerados na comparação de schemas semelhantes: os que definem as
+// propriedades e os tipos, sem restrições (required, enum, pattern, limites, format)
+var camposDeTipos = map[string]bool{
+	"type": true, "properties": true, "patternProperties": true, "additionalProperties": true, "items": true,
+	"allOf": true, "oneOf": true, "anyOf": true, "not": true, "$ref": true,
+}
+
+// Função para calcular o hash da estrutura de um schema: como hashDoNode, mas ignorando os campos de
+// documentação e a ordem das listas "required"; no nível semelhantes, apenas propriedades e tipos contam
+func hashEstrutural(node *yaml.Node, nivel semelhanca) string {
+	var buf bytes.Buffer
+	normalizarSchema(&buf, node, nivel, false, make(map[*yaml.Node]bool))
+	soma := sha256.Sum256(buf.Bytes())
+	return hex.EncodeToString(soma[:])
+}
+
+// Função para escrever a forma normalizada da estrutura de um schema; nomes indica que as chaves do
+// mapping são nomes de propriedades, que nunca são ignorados
+func normalizarSchema(buf *bytes.Buffer, node *yaml.Node, nivel semelhanca, nomes bool, emAndamento map[*yaml.Node]bool) {
+	node = resolverAlias(node)
+	if node == nil || emAndamento[node] {
+		buf.WriteString("null")
+		return
+	}
+	emAndamento[node] = true
+	defer delete(emAndamento, node)
+
+	switch node.Kind {
+	case yaml.DocumentNode:
+		normalizarSchema(buf, documento(node), nivel, false, emAndamento)
+	case yaml.MappingNode:
+		var pares []parYAML
+		for _, par := range paresDoMapa(node) {
+			chave := par.chave.Value
+			if !nomes && (camposDeDocumentacao[chave] || strings.HasPrefix(chave, "x-")) {
+				continue
+			}
+			if !nomes && nivel == semelhantes && !camposDeTipos[chave] {
+				continue
+			}
+			pares = append(pares, par)
+		}
+		sort.Slice(pares, func(i, j int) bool { return pares[i].chave.Value < pares[j].chave.Value })
+		buf.WriteByte('{')
+		for i, par := range pares {
+			if i > 0 {
+				buf.WriteByte(',')
+			}
+			fmt.Fprintf(buf, "%q:", par.chave.Value)
+			if !nomes && par.chave.Value == "required" {
+				if obrigatorios := textosDaLista(par.valor); obrigatorios != nil {
+					sort.Strings(obrigatorios)
+					fmt.Fprintf(buf, "%q", obrigatorios)
+					continue
+				}
+			}
+			normalizarSchema(buf, par.valor, nivel, !nomes && camposDeNomes[par.chave.Value], emAndamento)
+		}
+		buf.WriteByte('}')
+	case yaml.SequenceNode:
+		buf.WriteByte('[')
+		for i, item := range node.Content {
+			if i > 0 {
+				buf.WriteByte(',')
+			}
+			normalizarSchema(buf, item, nivel, false, emAndamento)
+		}
+		buf.WriteByte(']')
+	case yaml.ScalarNode:
+		buf.Write(escalarJSON(node))
+	}
+}
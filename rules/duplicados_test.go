@@ -0,0 +1,63 @@
+package main
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestAgruparSchemasDuplicados(t *testing.T) {
+	// RiskSignalsPayments/properties/manual e RiskSignalsConsents reproduzem o par do 2.0.0.yml: um é
+	// sub-schema, e os dois diferem apenas em descrições e em required
+	rootNode := mapaDeTeste(t, `
+openapi: 3.0.0
+components:
+  schemas:
+    RiskSignalsPayments:
+      type: object
+      properties:
+        manual:
+          type: object
+          required: [deviceId, osVersion]
+          properties:
+            deviceId: {type: string, description: Identificador do dispositivo.}
+            isRootedDevice: {type: boolean}
+            osVersion: {type: string}
+        automatic:
+          type: object
+          properties:
+            lastLoginDateTime: {type: string}
+    RiskSignalsConsents:
+      type: object
+      required: [deviceId, isRootedDevice, osVersion]
+      properties:
+        deviceId: {type: string, description: ID do dispositivo.}
+        isRootedDevice: {type: boolean}
+        osVersion: {type: string}
+    Conta:
+      type: object
+      properties: {ispb: {type: string}, number: {type: string}}
+    ContaCopia:
+      type: object
+      properties: {ispb: {type: string}, number: {type: string}}
+    Pagamento:
+      type: object
+      properties:
+        conta:
+          type: object
+          properties: {ispb: {type: string}, number: {type: string}}
+    Dia: {type: string, enum: [SEG, TER]}
+    Mes: {type: string, maxLength: 3}
+`)
+	schemas := []localSchema{}
+	for _, par := range paresDoMapa(valorDoMapa(valorDoMapa(rootNode, "components"), "schemas")) {
+		schemas = append(schemas, localSchema{ponteiro: "#/components/schemas/" + par.chave.Value, node: par.valor})
+	}
+
+	esperados := []grupoDuplicado{
+		{[]string{"#/components/schemas/Conta", "#/components/schemas/ContaCopia", "#/components/schemas/Pagamento/properties/conta"}, identicos},
+		{[]string{"#/components/schemas/RiskSignalsPayments/properties/manual", "#/components/schemas/RiskSignalsConsents"}, semelhantes},
+	}
+	if grupos := agruparSchemasDuplicados(schemas, 2); !reflect.DeepEqual(grupos, esperados) {
+		t.Errorf("grupos = %v, esperado %v", grupos, esperados)
+	}
+}
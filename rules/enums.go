@@ -0,0 +1,195 @@
+package main
+
+import (
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// estilos de escrita aceitos na opção "case" da regra enum-casing
+var estilosDeEnum = map[string]*regexp.Regexp{
+	"upper-snake": regexp.MustCompile(`^[A-Z][A-Z0-9]*(_[A-Z0-9]+)*$`),
+	"lower-snake": regexp.MustCompile(`^[a-z][a-z0-9]*(_[a-z0-9]+)*$`),
+	"camel":       regexp.MustCompile(`^[a-z][a-zA-Z0-9]*$`),
+	"pascal":      regexp.MustCompile(`^[A-Z][a-zA-Z0-9]*$`),
+	"kebab":       regexp.MustCompile(`^[a-z][a-z0-9]*(-[a-z0-9]+)*$`),
+}
+
+// Função para obter o regex do estilo de escrita dos valores de enum, pelo nome em "case" ou por um "pattern" próprio
+func estiloDeEnum(opcoes map[string]interface{}) (*regexp.Regexp, string, error) {
+	if pattern, ok := opcoes["pattern"].(string); ok {
+		re, err := regexp.Compile(pattern)
+		if err != nil {
+			return nil, "", fmt.Errorf("pattern inválido %q: %v", pattern, err)
+		}
+		return re, pattern, nil
+	}
+	estilo, _ := opcoes["case"].(string)
+	if estilo == "" {
+		estilo = "upper-snake"
+	}
+	re, ok := estilosDeEnum[estilo]
+	if !ok {
+		return nil, "", fmt.Errorf("estilo de enum desconhecido %q, use upper-snake, lower-snake, camel, pascal ou kebab", estilo)
+	}
+	return re, estilo, nil
+}
+
+// Função para listar os valores de texto do enum; números, booleanos e null não têm estilo de escrita
+func valoresTextoDoEnum(schema *yaml.Node) []string {
+	var valores []string
+	for _, item := range itensDaLista(valorDoMapa(schema, "enum")) {
+		if item.Kind == yaml.ScalarNode && item.ShortTag() == "!!str" {
+			valores = append(valores, item.Value)
+		}
+	}
+	return valores
+}
+
+// Função para verificar se a descrição menciona o valor como palavra inteira
+func descricaoMenciona(descricao, valor string) bool {
+	re := regexp.MustCompile(`(^|[^A-Za-z0-9_])` + regexp.QuoteMeta(valor) + `([^A-Za-z0-9_]|$)`)
+	return re.MatchString(descricao)
+}
+
+// Função para aplicar uma verificação aos schemas com enum e aos seus sub-schemas
+func validarEnums(schema *yaml.Node, validationErrors *[]error, p *percurso, r regra, campo string, verificar func(schema *yaml.Node) []string) []error {
+	if temChave(schema, "enum") {
+		if problemas := verificar(schema); len(problemas) > 0 {
+			*validationErrors = append(*validationErrors, r.findingDetalhado(campo, strings.Join(problemas, "; ")+"."))
+		}
+	}
+	visitarFilhos(schema, p, campo, func(sub *yaml.Node, campo string) {
+		*validationErrors = validarEnums(sub, validationErrors, p, r, campo, verificar)
+	})
+	return *validationErrors
+}
+
+// Função para verificar se os valores de texto do enum seguem o estilo de escrita
+func verificarEstiloEnum(re *regexp.Regexp, estilo string) func(schema *yaml.Node) []string {
+	return func(schema *yaml.Node) []string {
+		var fora []string
+		for _, valor := range valoresTextoDoEnum(schema) {
+			if !re.MatchString(valor) {
+				fora = append(fora, valor)
+			}
+		}
+		if len(fora) == 0 {
+			return nil
+		}
+		return []string{fmt.Sprintf("Valores fora do estilo %s: %s", estilo, strings.Join(fora, ", "))}
+	}
+}
+
+// Função para verificar se o enum tem valores repetidos
+func verificarEnumDuplicado(schema *yaml.Node) []string {
+	contagem := make(map[string]int)
+	var repetidos []string
+	for _, item := range itensDaLista(valorDoMapa(schema, "enum")) {
+		chave := hashDoNode(item)
+		contagem[chave]++
+		if contagem[chave] == 2 {
+			repetidos = append(repetidos, item.Value)
+		}
+	}
+	if len(repetidos) == 0 {
+		return nil
+	}
+	return []string{fmt.Sprintf("Valores repetidos: %s", strings.Join(repetidos, ", "))}
+}
+
+// Função para verificar se o enum declara o tipo dos valores
+func verificarTipoEnum(schema *yaml.Node) []string {
+	if temChave(schema, "type") {
+		return nil
+	}
+	return []string{"O enum não declara type"}
+}
+
+// Função para verificar se o enum tem valores e, com a opção maxValues, se não passa do limite
+func verificarTamanhoEnum(maxValores int) func(schema *yaml.Node) []string {
+	return func(schema *yaml.Node) []string {
+		quantidade := len(itensDaLista(valorDoMapa(schema, "enum")))
+		switch {
+		case quantidade == 0:
+			return []string{"O enum está vazio"}
+		case maxValores > 0 && quantidade > maxValores:
+			return []string{fmt.Sprintf("O enum tem %d valores, acima do limite de %d", quantidade, maxValores)}
+		}
+		return nil
+	}
+}
+
+// Função para verificar se cada valor de texto do enum é mencionado na descrição do schema
+func verificarEnumDocumentado(schema *yaml.Node) []string {
+	descricao, _ := texto(valorDoMapa(schema, "description"))
+	var ausentes []string
+	for _, valor := range valoresTextoDoEnum(schema) {
+		if !descricaoMenciona(descricao, valor) {
+			ausentes = append(ausentes, valor)
+		}
+	}
+	if len(ausentes) == 0 {
+		return nil
+	}
+	return []string{fmt.Sprintf("Valores sem explicação na descrição: %s", strings.Join(ausentes, ", "))}
+}
+
+// Função para mapear os enums de todos os schemas do documento pelo JSON pointer
+func enumsDoDocumento(rootNode *yaml.Node) map[string][]string {
+	enums := make(map[string][]string)
+	for _, schema := range coletarSchemas(rootNode) {
+		percorrerSubschemas(schema.node, schema.ponteiro, func(node *yaml.Node, ponteiro string) {
+			if enum := valorDoMapa(node, "enum"); enum != nil {
+				enums[ponteiro] = textosDaLista(enum)
+			}
+		})
+	}
+	return enums
+}
+
+// Função para comparar os enums com os da versão anterior do documento, na mesma localização; valores
+// removidos quebram os clientes que os enviam, e valores adicionados, os que não os esperam
+func validarMudancasDeEnum(rootNode, anterior *yaml.Node, validationErrors *[]error, r regra) []error {
+	if anterior == nil {
+		return *validationErrors
+	}
+	atuais := enumsDoDocumento(rootNode)
+	antigos := enumsDoDocumento(anterior)
+	var ponteiros []string
+	for ponteiro := range atuais {
+		if _, ok := antigos[ponteiro]; ok {
+			ponteiros = append(ponteiros, ponteiro)
+		}
+	}
+	sort.Strings(ponteiros)
+
+	for _, ponteiro := range ponteiros {
+		var removidos, adicionados []string
+		for _, valor := range antigos[ponteiro] {
+			if !contemTexto(atuais[ponteiro], valor) {
+				removidos = append(removidos, valor)
+			}
+		}
+		for _, valor := range atuais[ponteiro] {
+			if !contemTexto(antigos[ponteiro], valor) {
+				adicionados = append(adicionados, valor)
+			}
+		}
+		var mudancas []string
+		if len(removidos) > 0 {
+			mudancas = append(mudancas, "removidos: "+strings.Join(removidos, ", "))
+		}
+		if len(adicionados) > 0 {
+			mudancas = append(mudancas, "adicionados: "+strings.Join(adicionados, ", "))
+		}
+		if len(mudancas) > 0 {
+			*validationErrors = append(*validationErrors, r.findingDetalhado(ponteiro,
+				fmt.Sprintf("Valores do enum alterados em relação à versão anterior (%s).", strings.Join(mudancas, "; "))))
+		}
+	}
+	return *validationErrors
+}
@@ -0,0 +1,222 @@
+package main
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/pb33f/libopenapi/index"
+	"gopkg.in/yaml.v3"
+)
+
+// schemaLocalizado é um schema já sem $ref junto com o JSON pointer de onde foi declarado
+type schemaLocalizado struct {
+	campo string
+	node  *yaml.Node
+}
+
+// Função para seguir o $ref de um schema, atualizando o JSON pointer quando o destino é local
+func localizarSchema(idx *index.SpecIndex, node *yaml.Node, campo string) schemaLocalizado {
+	destino, origem := derreferenciar(idx, node)
+	if origem != "" {
+		campo = origem
+	}
+	return schemaLocalizado{campo, destino}
+}
+
+// Função para listar o schema e os membros do seu allOf, que juntos definem as propriedades do objeto
+func partesDoSchema(idx *index.SpecIndex, schema schemaLocalizado) []schemaLocalizado {
+	partes := []schemaLocalizado{schema}
+	for i, membro := range itensDaLista(valorDoMapa(schema.node, "allOf")) {
+		parte := localizarSchema(idx, membro, schema.campo+"/allOf/"+strconv.Itoa(i))
+		if parte.node != nil {
+			partes = append(partes, partesDoSchema(idx, parte)...)
+		}
+	}
+	return partes
+}
+
+// Função para buscar uma propriedade do schema, inclusive nas partes do allOf
+func propriedadeDoSchema(idx *index.SpecIndex, schema schemaLocalizado, nome string) (schemaLocalizado, bool) {
+	for _, parte := range partesDoSchema(idx, schema) {
+		if node := valorDoMapa(valorDoMapa(parte.node, "properties"), nome); node != nil {
+			propriedade := localizarSchema(idx, node, parte.campo+"/properties/"+escaparPonteiro(nome))
+			return propriedade, propriedade.node != nil
+		}
+	}
+	return schemaLocalizado{}, false
+}
+
+// Função para verificar se o schema, ou uma das partes do allOf, declara o campo como obrigatório
+func obrigatorioNoSchema(idx *index.SpecIndex, schema schemaLocalizado, nome string) bool {
+	for _, parte := range partesDoSchema(idx, schema) {
+		if contemTexto(textosDaLista(valorDoMapa(parte.node, "required")), nome) {
+			return true
+		}
+	}
+	return false
+}
+
+// Função para obter o tipo declarado pelo schema ou por uma das partes do allOf
+func tipoDoSchema(idx *index.SpecIndex, schema schemaLocalizado) string {
+	for _, parte := range partesDoSchema(idx, schema) {
+		if tipo, ok := texto(valorDoMapa(parte.node, "type")); ok {
+			return tipo
+		}
+	}
+	return ""
+}
+
+// Função para comparar um schema com a estrutura esperada (type, required, properties e items, como em um
+// schema), retornando a descrição de cada diferença; caminho identifica a posição dentro da estrutura
+func conformarEstrutura(idx *index.SpecIndex, schema schemaLocalizado, esperado map[string]interface{}, caminho string) []string {
+	var problemas []string
+	if tipo, ok := esperado["type"].(string); ok {
+		if atual := tipoDoSchema(idx, schema); atual != tipo {
+			problemas = append(problemas, fmt.Sprintf("%s deve ser do tipo %s, encontrado %q", caminho, tipo, atual))
+		}
+	}
+	for _, nome := range textosDaOpcao(esperado["required"]) {
+		if !obrigatorioNoSchema(idx, schema, nome) {
+			problemas = append(problemas, fmt.Sprintf("%s deve declarar %s como obrigatório", caminho, nome))
+		}
+	}
+	propriedades, _ := esperado["properties"].(map[string]interface{})
+	for _, nome := range chavesDaOpcao(propriedades) {
+		propriedade, ok := propriedadeDoSchema(idx, schema, nome)
+		if !ok {
+			problemas = append(problemas, fmt.Sprintf("%s deve ter a propriedade %s", caminho, nome))
+			continue
+		}
+		if estrutura, ok := propriedades[nome].(map[string]interface{}); ok {
+			problemas = append(problemas, conformarEstrutura(idx, propriedade, estrutura, caminho+"."+nome)...)
+		}
+	}
+	if estrutura, ok := esperado["items"].(map[string]interface{}); ok {
+		items := localizarSchema(idx, valorDoMapa(schema.node, "items"), schema.campo+"/items")
+		if items.node == nil {
+			problemas = append(problemas, fmt.Sprintf("%s deve declarar items", caminho))
+		} else {
+			problemas = append(problemas, conformarEstrutura(idx, items, estrutura, caminho+"[]")...)
+		}
+	}
+	return problemas
+}
+
+// Função para listar as responses das operações cujo status code corresponde a um dos padrões, sem repetir
+// responses compartilhadas por $ref
+func respostasComStatus(doc *yaml.Node, idx *index.SpecIndex, padroes []string) []declaracao {
+	var respostas []declaracao
+	vistas := make(map[string]bool)
+	for _, op := range listarOperacoes(doc) {
+		for _, resposta := range respostasDaOperacao(idx, op) {
+			if vistas[resposta.campo] {
+				continue
+			}
+			for _, padrao := range padroes {
+				if statusCorresponde(resposta.nome, padrao) {
+					vistas[resposta.campo] = true
+					respostas = append(respostas, resposta)
+					break
+				}
+			}
+		}
+	}
+	return respostas
+}
+
+// Função para listar os schemas do corpo de uma response, um por media type
+func schemasDaResposta(idx *index.SpecIndex, resposta declaracao) []schemaLocalizado {
+	var schemas []schemaLocalizado
+	for _, par := range paresDoMapa(valorDoMapa(resposta.node, "content")) {
+		campo := resposta.campo + "/content/" + escaparPonteiro(par.chave.Value) + "/schema"
+		if schema := localizarSchema(idx, valorDoMapa(par.valor, "schema"), campo); schema.node != nil {
+			schemas = append(schemas, schema)
+		}
+	}
+	return schemas
+}
+
+// Função para obter o schema do campo code dos itens de errors em um schema de erro
+func codigoDoErro(idx *index.SpecIndex, schema schemaLocalizado) (schemaLocalizado, bool) {
+	erros, ok := propriedadeDoSchema(idx, schema, "errors")
+	if !ok {
+		return schemaLocalizado{}, false
+	}
+	items := localizarSchema(idx, valorDoMapa(erros.node, "items"), erros.campo+"/items")
+	if items.node == nil {
+		return schemaLocalizado{}, false
+	}
+	return propriedadeDoSchema(idx, items, "code")
+}
+
+// Função para validar se as responses de erro usam a estrutura de erro canônica da regra
+func validarRespostasDeErro(doc *yaml.Node, idx *index.SpecIndex, validationErrors *[]error, r regra) []error {
+	opcoes := r.opcoes()
+	estrutura, _ := opcoes["schema"].(map[string]interface{})
+	vistos := make(map[string]bool)
+	for _, resposta := range respostasComStatus(doc, idx, textosDaOpcao(opcoes["statusCodes"])) {
+		schemas := schemasDaResposta(idx, resposta)
+		if len(schemas) == 0 {
+			*validationErrors = append(*validationErrors, r.findingDetalhado(resposta.campo,
+				fmt.Sprintf("A response %s não declara um schema de erro.", resposta.nome)))
+			continue
+		}
+		for _, schema := range schemas {
+			if vistos[schema.campo] {
+				continue
+			}
+			vistos[schema.campo] = true
+			if problemas := conformarEstrutura(idx, schema, estrutura, "schema"); len(problemas) > 0 {
+				*validationErrors = append(*validationErrors, r.findingDetalhado(schema.campo,
+					fmt.Sprintf("Na response %s, %s.", resposta.nome, strings.Join(problemas, "; "))))
+			}
+		}
+	}
+	return *validationErrors
+}
+
+// Função para validar se o campo code das responses de erro enumera os códigos de negócio
+func validarCodigosEnumerados(doc *yaml.Node, idx *index.SpecIndex, validationErrors *[]error, r regra) []error {
+	vistos := make(map[string]bool)
+	for _, resposta := range respostasComStatus(doc, idx, textosDaOpcao(r.opcoes()["statusCodes"])) {
+		for _, schema := range schemasDaResposta(idx, resposta) {
+			codigo, ok := codigoDoErro(idx, schema)
+			if !ok || vistos[codigo.campo] {
+				continue
+			}
+			vistos[codigo.campo] = true
+			if len(itensDaLista(valorDoMapa(codigo.node, "enum"))) == 0 {
+				*validationErrors = append(*validationErrors, r.findingDetalhado(codigo.campo,
+					fmt.Sprintf("O campo code da response %s não enumera os códigos de erro.", resposta.nome)))
+			}
+		}
+	}
+	return *validationErrors
+}
+
+// Função para validar se cada código do enum de code é explicado na descrição do campo
+func validarCodigosDocumentados(doc *yaml.Node, idx *index.SpecIndex, validationErrors *[]error, r regra) []error {
+	vistos := make(map[string]bool)
+	for _, resposta := range respostasComStatus(doc, idx, textosDaOpcao(r.opcoes()["statusCodes"])) {
+		for _, schema := range schemasDaResposta(idx, resposta) {
+			codigo, ok := codigoDoErro(idx, schema)
+			if !ok || vistos[codigo.campo] {
+				continue
+			}
+			vistos[codigo.campo] = true
+			descricao, _ := texto(valorDoMapa(codigo.node, "description"))
+			var ausentes []string
+			for _, valor := range textosDaLista(valorDoMapa(codigo.node, "enum")) {
+				if !strings.Contains(descricao, valor) {
+					ausentes = append(ausentes, valor)
+				}
+			}
+			if len(ausentes) > 0 {
+				*validationErrors = append(*validationErrors, r.findingDetalhado(codigo.campo,
+					fmt.Sprintf("Códigos sem explicação na descrição: %s.", strings.Join(ausentes, ", "))))
+			}
+		}
+	}
+	return *validationErrors
+}
@@ -0,0 +1,286 @@
+package main
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	neturl "net/url"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/pb33f/libopenapi/index"
+	"github.com/santhosh-tekuri/jsonschema/v6"
+	"golang.org/x/text/language"
+	"golang.org/x/text/message"
+	"gopkg.in/yaml.v3"
+)
+
+// validadorDeExemplos valida exemplos contra os schemas do documento com um validador JSON Schema; o documento
+// inteiro é registrado como recurso, então os $refs locais e os de outros arquivos são resolvidos pelo validador
+type validadorDeExemplos struct {
+	compilador *jsonschema.Compiler
+	url        string
+	versao     versaoSpec
+	compilados map[string]*jsonschema.Schema
+	falhas     map[string]error
+}
+
+// exemplo é um valor de exemplo junto com o JSON pointer da sua localização e o do schema que ele deve respeitar
+type exemplo struct {
+	campo  string
+	schema string
+	node   *yaml.Node
+}
+
+// regexpTolerante aceita qualquer valor; é usado nos patterns que o Go não compila (ex.: lookahead do ECMA-262),
+// que são reportados pelas regras de pattern e não devem gerar falsos erros nos exemplos
+type regexpTolerante string
+
+func (r regexpTolerante) String() string          { return string(r) }
+func (r regexpTolerante) MatchString(string) bool { return true }
+
+// Função para compilar os patterns dos schemas, usando o regexpTolerante quando o Go não consegue compilá-los
+func compilarRegexpTolerante(pattern string) (jsonschema.Regexp, error) {
+	if re, err := regexp.Compile(pattern); err == nil {
+		return re, nil
+	}
+	return regexpTolerante(pattern), nil
+}
+
+// carregadorYAML carrega os arquivos referenciados pelos schemas, em YAML ou JSON, com as mesmas conversões
+// aplicadas ao documento principal
+type carregadorYAML struct {
+	versao versaoSpec
+}
+
+func (c carregadorYAML) Load(url string) (interface{}, error) {
+	caminho, err := jsonschema.FileLoader{}.ToFile(url)
+	if err != nil {
+		return nil, err
+	}
+	data, err := readFile(caminho)
+	if err != nil {
+		return nil, err
+	}
+	node, _, err := lerDocumento(data)
+	if err != nil {
+		return nil, err
+	}
+	return nodeParaJSONSchema(node, c.versao)
+}
+
+// Função para converter um nó no valor JSON usado pelo validador; no OpenAPI 3.0 o "nullable: true" vira
+// o tipo "null", que é como o JSON Schema representa valores nulos
+func nodeParaJSONSchema(node *yaml.Node, versao versaoSpec) (interface{}, error) {
+	var buf bytes.Buffer
+	if err := escreverJSON(&buf, node, make(map[*yaml.Node]bool)); err != nil {
+		return nil, err
+	}
+	valor, err := jsonschema.UnmarshalJSON(&buf)
+	if err != nil {
+		return nil, err
+	}
+	if versao == versaoOAS3_0 {
+		converterNullable(valor)
+	}
+	return valor, nil
+}
+
+// Função para converter o "nullable: true" do OpenAPI 3.0 em todos os objetos do documento
+func converterNullable(valor interface{}) {
+	switch v := valor.(type) {
+	case map[string]interface{}:
+		if nullable, _ := v["nullable"].(bool); nullable {
+			if tipo, ok := v["type"].(string); ok {
+				v["type"] = []interface{}{tipo, "null"}
+			}
+			if enum, ok := v["enum"].([]interface{}); ok {
+				v["enum"] = append(enum, nil)
+			}
+		}
+		for _, filho := range v {
+			converterNullable(filho)
+		}
+	case []interface{}:
+		for _, filho := range v {
+			converterNullable(filho)
+		}
+	}
+}
+
+// Função para montar a URL de um fragmento JSON pointer, escapando cada segmento
+func urlDoPonteiro(base, ponteiro string) string {
+	segmentos := strings.Split(strings.TrimPrefix(ponteiro, "#"), "/")
+	for i, segmento := range segmentos {
+		segmentos[i] = neturl.PathEscape(segmento)
+	}
+	return base + "#" + strings.Join(segmentos, "/")
+}
+
+// Função para criar o validador de exemplos do documento
+func novoValidadorDeExemplos(rootNode *yaml.Node, idx *index.SpecIndex) (*validadorDeExemplos, error) {
+	versao, _ := detectarVersao(documento(rootNode))
+	doc, err := nodeParaJSONSchema(rootNode, versao)
+	if err != nil {
+		return nil, err
+	}
+
+	c := jsonschema.NewCompiler()
+	c.DefaultDraft(jsonschema.Draft4)
+	if versao == versaoOAS3_1 {
+		c.DefaultDraft(jsonschema.Draft2020)
+	}
+	c.AssertFormat()
+	c.UseRegexpEngine(compilarRegexpTolerante)
+	c.UseLoader(carregadorYAML{versao})
+
+	url := "file:///openapi.json"
+	if idx != nil && idx.GetSpecAbsolutePath() != "" {
+		url = (&neturl.URL{Scheme: "file", Path: idx.GetSpecAbsolutePath()}).String()
+	}
+	if err := c.AddResource(url, doc); err != nil {
+		return nil, err
+	}
+	return &validadorDeExemplos{
+		compilador: c,
+		url:        url,
+		versao:     versao,
+		compilados: make(map[string]*jsonschema.Schema),
+		falhas:     make(map[string]error),
+	}, nil
+}
+
+// Função para compilar o schema do JSON pointer, uma única vez por schema
+func (v *validadorDeExemplos) schema(ponteiro string) (*jsonschema.Schema, error) {
+	if schema, ok := v.compilados[ponteiro]; ok {
+		return schema, nil
+	}
+	if err, ok := v.falhas[ponteiro]; ok {
+		return nil, err
+	}
+	schema, err := v.compilador.Compile(urlDoPonteiro(v.url, ponteiro))
+	if err != nil {
+		v.falhas[ponteiro] = err
+		return nil, err
+	}
+	v.compilados[ponteiro] = schema
+	return schema, nil
+}
+
+// Função para validar um exemplo, retornando a descrição de cada divergência com a localização dentro do exemplo
+func (v *validadorDeExemplos) validar(e exemplo) ([]string, error) {
+	schema, err := v.schema(e.schema)
+	if err != nil {
+		return nil, err
+	}
+	valor, err := nodeParaJSONSchema(e.node, versaoDesconhecida)
+	if err != nil {
+		return nil, err
+	}
+	err = schema.Validate(valor)
+	var erroValidacao *jsonschema.ValidationError
+	if !errors.As(err, &erroValidacao) {
+		return nil, err
+	}
+	var divergencias []string
+	impressora := message.NewPrinter(language.English)
+	var coletar func(e *jsonschema.ValidationError)
+	coletar = func(e *jsonschema.ValidationError) {
+		if len(e.Causes) == 0 {
+			local := "/" + strings.Join(e.InstanceLocation, "/")
+			divergencias = append(divergencias, fmt.Sprintf("em %s: %s", local, e.ErrorKind.LocalizedString(impressora)))
+			return
+		}
+		for _, causa := range e.Causes {
+			coletar(causa)
+		}
+	}
+	coletar(erroValidacao)
+	return divergencias, nil
+}
+
+// Função para coletar os exemplos declarados em um schema e nos seus sub-schemas
+func coletarExemplosDoSchema(node *yaml.Node, ponteiro string, exemplos []exemplo) []exemplo {
+	percorrerSubschemas(node, ponteiro, func(schema *yaml.Node, ponteiro string) {
+		if valor := valorDoMapa(schema, "example"); valor != nil {
+			exemplos = append(exemplos, exemplo{campo: ponteiro + "/example", schema: ponteiro, node: valor})
+		}
+		// OpenAPI 3.1: "examples" do JSON Schema é uma lista de valores
+		for i, valor := range itensDaLista(valorDoMapa(schema, "examples")) {
+			exemplos = append(exemplos, exemplo{campo: ponteiro + "/examples/" + strconv.Itoa(i), schema: ponteiro, node: valor})
+		}
+	})
+	return exemplos
+}
+
+// Função para coletar os exemplos do objeto que declara o schema: media type, parâmetro ou header; no OpenAPI 3
+// "examples" é um mapa de Example Objects (que podem ser $refs) e no Swagger 2.0 um mapa de media type para valor
+func coletarExemplosDoPai(rootNode *yaml.Node, idx *index.SpecIndex, schema localSchema, versao versaoSpec, exemplos []exemplo) []exemplo {
+	if !strings.HasSuffix(schema.ponteiro, "/schema") {
+		return exemplos
+	}
+	pai := strings.TrimSuffix(schema.ponteiro, "/schema")
+	node := nodeDoPonteiro(rootNode, pai)
+	if valor := valorDoMapa(node, "example"); valor != nil {
+		exemplos = append(exemplos, exemplo{campo: pai + "/example", schema: schema.ponteiro, node: valor})
+	}
+	if versao == versaoOAS2 {
+		// no Swagger 2.0 os exemplos ficam na response, ao lado do schema
+		for _, par := range paresDoMapa(valorDoMapa(node, "examples")) {
+			campo := pai + "/examples/" + escaparPonteiro(par.chave.Value)
+			exemplos = append(exemplos, exemplo{campo: campo, schema: schema.ponteiro, node: par.valor})
+		}
+		return exemplos
+	}
+	for _, par := range paresDoMapa(valorDoMapa(node, "examples")) {
+		campo := pai + "/examples/" + escaparPonteiro(par.chave.Value)
+		objeto, origem := derreferenciar(idx, par.valor)
+		if origem != "" {
+			campo = origem
+		}
+		if valor := valorDoMapa(objeto, "value"); valor != nil {
+			exemplos = append(exemplos, exemplo{campo: campo + "/value", schema: schema.ponteiro, node: valor})
+		}
+	}
+	return exemplos
+}
+
+// Função para validar os exemplos dos schemas e dos media types, parâmetros e headers contra os seus schemas
+func validarExemplos(rootNode *yaml.Node, idx *index.SpecIndex, schemas []localSchema, validationErrors *[]error, r regra) []error {
+	validador, err := novoValidadorDeExemplos(rootNode, idx)
+	if err != nil {
+		*validationErrors = append(*validationErrors, r.findingDetalhado("", fmt.Sprintf("Não foi possível preparar o validador: %v", err)))
+		return *validationErrors
+	}
+	var exemplos []exemplo
+	for _, schema := range schemas {
+		exemplos = coletarExemplosDoSchema(schema.node, schema.ponteiro, exemplos)
+		exemplos = coletarExemplosDoPai(rootNode, idx, schema, validador.versao, exemplos)
+	}
+
+	vistos := make(map[string]bool)
+	for _, e := range exemplos {
+		// um Example Object compartilhado por $ref é validado uma vez para cada schema
+		if vistos[e.campo+" "+e.schema] {
+			continue
+		}
+		vistos[e.campo+" "+e.schema] = true
+		divergencias, err := validador.validar(e)
+		if err != nil {
+			// a falha ao compilar um schema é reportada uma única vez
+			if vistos[e.schema] {
+				continue
+			}
+			vistos[e.schema] = true
+			*validationErrors = append(*validationErrors, r.findingDetalhado(e.campo,
+				fmt.Sprintf("Não foi possível validar o exemplo contra %s: %v", e.schema, err)))
+			continue
+		}
+		if len(divergencias) > 0 {
+			*validationErrors = append(*validationErrors, r.findingDetalhado(e.campo,
+				fmt.Sprintf("O exemplo não respeita o schema %s: %s.", e.schema, strings.Join(divergencias, "; "))))
+		}
+	}
+	return *validationErrors
+}
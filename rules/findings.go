@@ -0,0 +1,143 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"regexp"
+	"strings"
+
+	"github.com/pb33f/libopenapi/index"
+	"gopkg.in/yaml.v3"
+)
+
+// regra representa uma regra personalizada carregada do pb33f_rules.yaml
+type regra struct {
+	nome     string
+	severity string
+	contexto contexto
+	formatos []versaoSpec
+	dados    map[string]interface{}
+}
+
+// finding representa um problema encontrado por uma regra no documento
+type finding struct {
+	regra      string
+	severidade string
+	campo      string
+	descricao  string
+	linha      int
+	coluna     int
+}
+
+func (f *finding) Error() string {
+	if f.campo == "" {
+		return fmt.Sprintf("[%s] %s: %s", f.severidade, f.regra, f.descricao)
+	}
+	if f.linha > 0 {
+		return fmt.Sprintf("[%s] %s: campo: %s (linha %d, coluna %d) - %s", f.severidade, f.regra, f.campo, f.linha, f.coluna, f.descricao)
+	}
+	return fmt.Sprintf("[%s] %s: campo: %s - %s", f.severidade, f.regra, f.campo, f.descricao)
+}
+
+// Função para criar um finding da regra, apontando para o campo informado (opcional)
+func (r regra) finding(campo string) error {
+	return &finding{
+		regra:      r.nome,
+		severidade: r.severity,
+		campo:      campo,
+		descricao:  fmt.Sprint(r.dados["description"]),
+	}
+}
+
+// Função para criar um finding da regra com o detalhe do problema encontrado após a descrição
+func (r regra) findingDetalhado(campo, detalhe string) error {
+	return &finding{
+		regra:      r.nome,
+		severidade: r.severity,
+		campo:      campo,
+		descricao:  fmt.Sprintf("%v %s", r.dados["description"], detalhe),
+	}
+}
+
+// Função para obter as opções da regra, declaradas em then.functionOptions
+func (r regra) opcoes() map[string]interface{} {
+	then, _ := r.dados["then"].(map[string]interface{})
+	opcoes, _ := then["functionOptions"].(map[string]interface{})
+	return opcoes
+}
+
+// Função para criar uma regra a partir dos dados do YAML
+func novaRegra(nome string, dados map[string]interface{}) (regra, error) {
+	severity, _ := dados["severity"].(string)
+	r := regra{nome: nome, severity: severity, dados: dados}
+
+	if valor, ok := dados["context"].(string); ok {
+		ctx, err := parseContexto(valor)
+		if err != nil {
+			return r, fmt.Errorf("regra %s: %v", nome, err)
+		}
+		r.contexto = ctx
+	}
+	if valor, ok := dados["formats"]; ok {
+		formatos, err := parseFormatos(valor)
+		if err != nil {
+			return r, fmt.Errorf("regra %s: %v", nome, err)
+		}
+		r.formatos = formatos
+	}
+	return r, nil
+}
+
+var severidadeErro = regexp.MustCompile(`\berror\b`)
+
+// Função para verificar se um erro de validação deve falhar a execução
+func isErro(err error) bool {
+	var f *finding
+	if errors.As(err, &f) {
+		return f.severidade == "error"
+	}
+	return severidadeErro.MatchString(err.Error())
+}
+
+// Função para criar um finding de parse, usado quando o documento não pode ser interpretado
+func findingDeParse(descricao string) error {
+	return &finding{regra: "parse", severidade: "error", descricao: descricao}
+}
+
+// Função para preencher a linha e a coluna dos findings a partir do JSON pointer do campo
+func localizarFindings(rootNode *yaml.Node, validationErrors []error) {
+	for _, err := range validationErrors {
+		var f *finding
+		if !errors.As(err, &f) || f.linha > 0 || !strings.HasPrefix(f.campo, "#") {
+			continue
+		}
+		if node := buscarPonteiro(rootNode, f.campo); node != nil {
+			f.linha, f.coluna = node.Line, node.Column
+		}
+	}
+}
+
+// Função para preencher a linha e a coluna dos findings que apontam para um arquivo referenciado
+// (ex.: schemas/Payment.yaml#/properties/nome), buscando o JSON pointer no documento do próprio arquivo
+func localizarFindingsExternos(rolodex *index.Rolodex, validationErrors []error) {
+	config := rolodex.GetConfig()
+	documentos := make(map[string]*yaml.Node)
+	for _, idx := range rolodex.GetIndexes() {
+		if idx != nil && idx.GetSpecAbsolutePath() != "" {
+			documentos[caminhoRelativo(idx.GetSpecAbsolutePath(), config.BasePath)] = idx.GetRootNode()
+		}
+	}
+	for _, err := range validationErrors {
+		var f *finding
+		if !errors.As(err, &f) || f.linha > 0 {
+			continue
+		}
+		arquivo, ponteiro, ok := strings.Cut(f.campo, "#")
+		if !ok || documentos[arquivo] == nil {
+			continue
+		}
+		if node := buscarPonteiro(documentos[arquivo], "#"+ponteiro); node != nil {
+			f.linha, f.coluna = node.Line, node.Column
+		}
+	}
+}
@@ -0,0 +1,432 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"unicode/utf8"
+
+	"gopkg.in/yaml.v3"
+)
+
+// formato representa o formato de serialização de um documento OpenAPI
+type formato string
+
+const (
+	formatoYAML formato = "yaml"
+	formatoJSON formato = "json"
+)
+
+// Função para detectar o formato do documento pelo primeiro caractere significativo
+func detectarFormato(data []byte) formato {
+	conteudo := bytes.TrimLeft(data, " \t\r\n")
+	if len(conteudo) > 0 && (conteudo[0] == '{' || conteudo[0] == '[') {
+		return formatoJSON
+	}
+	return formatoYAML
+}
+
+// Função para converter o valor da flag --output-format
+func parseFormato(valor string) (formato, error) {
+	switch strings.ToLower(valor) {
+	case "json":
+		return formatoJSON, nil
+	case "yaml", "yml":
+		return formatoYAML, nil
+	}
+	return "", fmt.Errorf("formato de saída inválido %q, use json ou yaml", valor)
+}
+
+// Função para escolher o formato de saída: a flag tem precedência, depois a extensão do arquivo de saída
+// e, por último, o formato do arquivo de entrada
+func escolherFormatoSaida(flagFormato string, arquivoSaida string, entrada formato) (formato, error) {
+	if flagFormato != "" {
+		return parseFormato(flagFormato)
+	}
+	switch strings.ToLower(filepath.Ext(arquivoSaida)) {
+	case ".json":
+		return formatoJSON, nil
+	case ".yaml", ".yml":
+		return formatoYAML, nil
+	}
+	return entrada, nil
+}
+
+// Função para interpretar um documento YAML ou JSON como árvore de nós YAML; um conteúdo que começa com { ou [
+// mas não é JSON válido é interpretado como YAML em estilo flow. Aliases recursivos são rejeitados, pois não têm
+// representação em JSON e fariam as regras percorrerem o documento indefinidamente
+func lerDocumento(data []byte) (*yaml.Node, formato, error) {
+	f := detectarFormato(data)
+	if f == formatoJSON {
+		rootNode, err := jsonParaNode(data)
+		if err == nil {
+			return rootNode, f, nil
+		}
+		var flow yaml.Node
+		if yaml.Unmarshal(data, &flow) != nil {
+			return nil, f, fmt.Errorf("erro ao interpretar o JSON: %v", err)
+		}
+		return validarAliases(&flow, formatoYAML)
+	}
+
+	var rootNode yaml.Node
+	if err := yaml.Unmarshal(data, &rootNode); err != nil {
+		return nil, f, fmt.Errorf("erro ao fazer unmarshal do YAML: %v", err)
+	}
+	return validarAliases(&rootNode, f)
+}
+
+// Função para rejeitar documentos com aliases recursivos (um alias dentro do próprio nó ancorado)
+func validarAliases(rootNode *yaml.Node, f formato) (*yaml.Node, formato, error) {
+	if alias := aliasRecursivo(rootNode); alias != nil {
+		return nil, f, fmt.Errorf("erro ao fazer unmarshal do YAML: alias recursivo *%s na linha %d", alias.Value, alias.Line)
+	}
+	return rootNode, f, nil
+}
+
+// indentacaoPadrao é usada quando a indentação do documento de entrada não pode ser detectada
+const indentacaoPadrao = 2
+
+// opcoesSaida configura a serialização dos documentos gerados pelos comandos resolve, bundle e convert
+type opcoesSaida struct {
+	formato    string // json ou yaml; vazio escolhe pela extensão do arquivo de saída
+	indentacao int    // espaços por nível; zero mantém a indentação do documento de entrada
+}
+
+// Função para escolher a indentação da saída: a flag tem precedência sobre a indentação do documento de entrada
+func escolherIndentacao(flagIndentacao int, data []byte) (int, error) {
+	if flagIndentacao < 0 || flagIndentacao > 8 {
+		return 0, fmt.Errorf("indentação inválida %d, use um valor entre 1 e 8", flagIndentacao)
+	}
+	if flagIndentacao > 0 {
+		return flagIndentacao, nil
+	}
+	return detectarIndentacao(data), nil
+}
+
+// Função para detectar a indentação do documento pela menor indentação entre as linhas com conteúdo;
+// linhas de comentário e documentos sem aninhamento usam a indentação padrão
+func detectarIndentacao(data []byte) int {
+	menor := 0
+	for _, linha := range strings.Split(string(data), "\n") {
+		conteudo := strings.TrimLeft(linha, " ")
+		recuo := len(linha) - len(conteudo)
+		if recuo == 0 || strings.TrimSpace(conteudo) == "" || strings.HasPrefix(conteudo, "#") {
+			continue
+		}
+		if menor == 0 || recuo < menor {
+			menor = recuo
+		}
+	}
+	if menor == 0 || menor > 8 {
+		return indentacaoPadrao
+	}
+	return menor
+}
+
+// Função para serializar o documento no formato informado, preservando a ordem das chaves, os comentários
+// e o estilo dos escalares (blocos literais, aspas) do YAML
+func codificarDocumento(rootNode *yaml.Node, f formato, indentacao int) ([]byte, error) {
+	if f == formatoJSON {
+		var buf bytes.Buffer
+		if err := escreverJSON(&buf, rootNode, make(map[*yaml.Node]bool)); err != nil {
+			return nil, err
+		}
+		var indentado bytes.Buffer
+		if err := json.Indent(&indentado, buf.Bytes(), "", strings.Repeat(" ", indentacao)); err != nil {
+			return nil, fmt.Errorf("erro ao converter para JSON: %v", err)
+		}
+		indentado.WriteByte('\n')
+		return indentado.Bytes(), nil
+	}
+
+	// o marcador só é usado se não aparecer no documento, para que a troca de volta não altere outros textos
+	var protegidos map[*yaml.Node]string
+	if !contemMarcador(rootNode, make(map[*yaml.Node]bool)) {
+		protegidos = make(map[*yaml.Node]string)
+		protegerEspacosFinais(rootNode, protegidos, make(map[*yaml.Node]bool))
+	}
+	defer func() {
+		for node, valor := range protegidos {
+			node.Value = valor
+		}
+	}()
+
+	var buf bytes.Buffer
+	encoder := yaml.NewEncoder(&buf)
+	encoder.SetIndent(indentacao)
+	if err := encoder.Encode(rootNode); err != nil {
+		return nil, fmt.Errorf("erro ao converter para YAML: %v", err)
+	}
+	if err := encoder.Close(); err != nil {
+		return nil, fmt.Errorf("erro ao converter para YAML: %v", err)
+	}
+	if protegidos == nil {
+		return buf.Bytes(), nil
+	}
+	return bytes.ReplaceAll(buf.Bytes(), []byte(marcadorEspaco), []byte(" ")), nil
+}
+
+// marcadorEspaco substitui temporariamente os espaços no fim das linhas dos blocos literais; o yaml.v3 troca
+// por uma string entre aspas duplas qualquer bloco com espaços no fim de uma linha, o que é comum em descrições
+// Markdown. O caractere é de uso privado do Unicode e não aparece em documentos reais
+const marcadorEspaco = "\uE000"
+
+// Função para verificar se algum escalar do documento contém o marcador de espaço
+func contemMarcador(node *yaml.Node, vistos map[*yaml.Node]bool) bool {
+	if node == nil || vistos[node] {
+		return false
+	}
+	vistos[node] = true
+	if strings.Contains(node.Value, marcadorEspaco) {
+		return true
+	}
+	for _, filho := range node.Content {
+		if contemMarcador(filho, vistos) {
+			return true
+		}
+	}
+	return false
+}
+
+// Função para trocar pelo marcador os espaços no fim das linhas dos blocos literais e dobrados, guardando
+// o valor original de cada nó alterado
+func protegerEspacosFinais(node *yaml.Node, protegidos map[*yaml.Node]string, vistos map[*yaml.Node]bool) {
+	if node == nil || vistos[node] {
+		return
+	}
+	vistos[node] = true
+	if node.Kind == yaml.ScalarNode && node.Style&(yaml.LiteralStyle|yaml.FoldedStyle) != 0 {
+		linhas := strings.Split(node.Value, "\n")
+		alterado := false
+		for i, linha := range linhas {
+			semEspacos := strings.TrimRight(linha, " ")
+			if semEspacos != linha && i < len(linhas)-1 {
+				linhas[i] = semEspacos + strings.Repeat(marcadorEspaco, len(linha)-len(semEspacos))
+				alterado = true
+			}
+		}
+		if alterado {
+			protegidos[node] = node.Value
+			node.Value = strings.Join(linhas, "\n")
+		}
+		return
+	}
+	for _, filho := range node.Content {
+		protegerEspacosFinais(filho, protegidos, vistos)
+	}
+}
+
+// Função para escrever um nó como JSON, mantendo a ordem das chaves e expandindo aliases e merge keys
+func escreverJSON(buf *bytes.Buffer, node *yaml.Node, emAndamento map[*yaml.Node]bool) error {
+	node = resolverAlias(node)
+	if node == nil {
+		buf.WriteString("null")
+		return nil
+	}
+	if emAndamento[node] {
+		return fmt.Errorf("erro ao converter para JSON: alias recursivo na linha %d", node.Line)
+	}
+	emAndamento[node] = true
+	defer delete(emAndamento, node)
+
+	switch node.Kind {
+	case yaml.DocumentNode:
+		if len(node.Content) == 0 {
+			buf.WriteString("null")
+			return nil
+		}
+		return escreverJSON(buf, node.Content[0], emAndamento)
+
+	case yaml.MappingNode:
+		buf.WriteByte('{')
+		for i, par := range paresDoMapa(node) {
+			if i > 0 {
+				buf.WriteByte(',')
+			}
+			chave, _ := json.Marshal(par.chave.Value)
+			buf.Write(chave)
+			buf.WriteByte(':')
+			if err := escreverJSON(buf, par.valor, emAndamento); err != nil {
+				return err
+			}
+		}
+		buf.WriteByte('}')
+
+	case yaml.SequenceNode:
+		buf.WriteByte('[')
+		for i, item := range node.Content {
+			if i > 0 {
+				buf.WriteByte(',')
+			}
+			if err := escreverJSON(buf, item, emAndamento); err != nil {
+				return err
+			}
+		}
+		buf.WriteByte(']')
+
+	case yaml.ScalarNode:
+		buf.Write(escalarJSON(node))
+	}
+	return nil
+}
+
+// numeroJSON reconhece a sintaxe de número do JSON (RFC 8259)
+var numeroJSON = regexp.MustCompile(`^-?(0|[1-9][0-9]*)(\.[0-9]+)?([eE][+-]?[0-9]+)?$`)
+
+// Função para converter um escalar YAML no valor JSON equivalente; números já escritos na sintaxe do JSON
+// são mantidos como estão (ex.: 1.5e3 não vira 1500)
+func escalarJSON(node *yaml.Node) []byte {
+	switch node.ShortTag() {
+	case "!!null":
+		return []byte("null")
+	case "!!int", "!!float":
+		if numeroJSON.MatchString(node.Value) {
+			return []byte(node.Value)
+		}
+		fallthrough
+	case "!!bool":
+		var valor interface{}
+		if err := node.Decode(&valor); err == nil {
+			if data, err := json.Marshal(valor); err == nil {
+				return data
+			}
+		}
+	}
+	data, _ := json.Marshal(node.Value)
+	return data
+}
+
+// leitorJSON converte tokens JSON em nós YAML, registrando a linha e coluna de cada valor
+type leitorJSON struct {
+	data    []byte
+	dec     *json.Decoder
+	inicios []int // offset do início de cada linha
+}
+
+// Função para converter um documento JSON em uma árvore de nós YAML com posições exatas
+func jsonParaNode(data []byte) (*yaml.Node, error) {
+	l := &leitorJSON{data: data, dec: json.NewDecoder(bytes.NewReader(data)), inicios: []int{0}}
+	l.dec.UseNumber()
+	for i, c := range data {
+		if c == '\n' {
+			l.inicios = append(l.inicios, i+1)
+		}
+	}
+
+	tok, inicio, err := l.proximo()
+	if err != nil {
+		return nil, err
+	}
+	raiz, err := l.valor(tok, inicio)
+	if err != nil {
+		return nil, err
+	}
+	if _, _, err := l.proximo(); err != io.EOF {
+		linha, coluna := l.posicao(int(l.dec.InputOffset()))
+		return nil, fmt.Errorf("conteúdo inesperado após o fim do documento na linha %d, coluna %d", linha, coluna)
+	}
+	return &yaml.Node{Kind: yaml.DocumentNode, Line: 1, Column: 1, Content: []*yaml.Node{raiz}}, nil
+}
+
+// Função para ler o próximo token e o offset onde ele começa
+func (l *leitorJSON) proximo() (json.Token, int, error) {
+	inicio := int(l.dec.InputOffset())
+	tok, err := l.dec.Token()
+	for inicio < len(l.data) && strings.IndexByte(" \t\r\n,:", l.data[inicio]) >= 0 {
+		inicio++
+	}
+
+	var sintaxe *json.SyntaxError
+	if errors.As(err, &sintaxe) {
+		// o offset do erro já inclui o caractere inválido
+		linha, coluna := l.posicao(int(sintaxe.Offset) - 1)
+		err = fmt.Errorf("%v na linha %d, coluna %d", err, linha, coluna)
+	}
+	return tok, inicio, err
+}
+
+// Função para converter um offset em linha e coluna (começando em 1, como no yaml.v3)
+func (l *leitorJSON) posicao(offset int) (int, int) {
+	linha := 0
+	for linha+1 < len(l.inicios) && l.inicios[linha+1] <= offset {
+		linha++
+	}
+	inicioLinha := l.inicios[linha]
+	if offset > len(l.data) {
+		offset = len(l.data)
+	}
+	if offset < inicioLinha {
+		offset = inicioLinha
+	}
+	return linha + 1, utf8.RuneCount(l.data[inicioLinha:offset]) + 1
+}
+
+// Função para converter um token (e, para objetos e arrays, os tokens seguintes) em um nó YAML
+func (l *leitorJSON) valor(tok json.Token, inicio int) (*yaml.Node, error) {
+	linha, coluna := l.posicao(inicio)
+	node := &yaml.Node{Line: linha, Column: coluna}
+
+	switch v := tok.(type) {
+	case json.Delim:
+		switch v {
+		case '{':
+			node.Kind, node.Tag = yaml.MappingNode, "!!map"
+			for l.dec.More() {
+				chaveTok, chaveInicio, err := l.proximo()
+				if err != nil {
+					return nil, err
+				}
+				chave, err := l.valor(chaveTok, chaveInicio)
+				if err != nil {
+					return nil, err
+				}
+				valorTok, valorInicio, err := l.proximo()
+				if err != nil {
+					return nil, err
+				}
+				valor, err := l.valor(valorTok, valorInicio)
+				if err != nil {
+					return nil, err
+				}
+				node.Content = append(node.Content, chave, valor)
+			}
+		case '[':
+			node.Kind, node.Tag = yaml.SequenceNode, "!!seq"
+			for l.dec.More() {
+				itemTok, itemInicio, err := l.proximo()
+				if err != nil {
+					return nil, err
+				}
+				item, err := l.valor(itemTok, itemInicio)
+				if err != nil {
+					return nil, err
+				}
+				node.Content = append(node.Content, item)
+			}
+		default:
+			return nil, fmt.Errorf("delimitador inesperado %q na linha %d, coluna %d", v, linha, coluna)
+		}
+		// consome o delimitador de fechamento
+		if _, _, err := l.proximo(); err != nil {
+			return nil, err
+		}
+	case string:
+		node.Kind, node.Tag, node.Value = yaml.ScalarNode, "!!str", v
+	case json.Number:
+		node.Kind, node.Tag, node.Value = yaml.ScalarNode, "!!int", v.String()
+		if strings.ContainsAny(v.String(), ".eE") {
+			node.Tag = "!!float"
+		}
+	case bool:
+		node.Kind, node.Tag, node.Value = yaml.ScalarNode, "!!bool", fmt.Sprint(v)
+	case nil:
+		node.Kind, node.Tag, node.Value = yaml.ScalarNode, "!!null", "null"
+	}
+	return node, nil
+}
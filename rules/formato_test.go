@@ -0,0 +1,147 @@
+package main
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+
+	"gopkg.in/yaml.v3"
+)
+
+func TestJSONParaNodePosicoes(t *testing.T) {
+	data := []byte(`{
+  "info": {"title": "Pagamentos", "version": "1"},
+  "tags": [
+    "a",
+    {"name": "b"}
+  ],
+  "x-texto": "a\u00e9b", "x-depois": 1,
+  "x-emoji": "\ud83d\ude00", "x-fim": true,
+  "x-literal": "é", "x-apos": null
+}`)
+	rootNode, err := jsonParaNode(data)
+	if err != nil {
+		t.Fatalf("erro ao interpretar o JSON: %v", err)
+	}
+	doc := documento(rootNode)
+
+	// Função para obter o nó da chave, que valorDoMapa não retorna
+	chaveDoMapa := func(mapa *yaml.Node, chave string) *yaml.Node {
+		for i := 0; i+1 < len(mapa.Content); i += 2 {
+			if mapa.Content[i].Value == chave {
+				return mapa.Content[i]
+			}
+		}
+		return nil
+	}
+	tags := valorDoMapa(doc, "tags")
+	casos := []struct {
+		nome          string
+		node          *yaml.Node
+		linha, coluna int
+	}{
+		{"raiz", doc, 1, 1},
+		{"chave info", chaveDoMapa(doc, "info"), 2, 3},
+		{"valor info", valorDoMapa(doc, "info"), 2, 11},
+		{"chave aninhada", chaveDoMapa(valorDoMapa(doc, "info"), "version"), 2, 35},
+		{"valor aninhado", valorDoMapa(valorDoMapa(doc, "info"), "version"), 2, 46},
+		{"primeiro item", tags.Content[0], 4, 5},
+		{"item objeto", tags.Content[1], 5, 5},
+		{"chave no item", chaveDoMapa(tags.Content[1], "name"), 5, 6},
+		{"string com \\u", valorDoMapa(doc, "x-texto"), 7, 14},
+		{"depois de \\u", chaveDoMapa(doc, "x-depois"), 7, 26},
+		{"depois de par substituto", chaveDoMapa(doc, "x-fim"), 8, 30},
+		{"depois de caractere multibyte", chaveDoMapa(doc, "x-apos"), 9, 21},
+	}
+	for _, c := range casos {
+		if c.node == nil {
+			t.Errorf("%s: nó não encontrado", c.nome)
+			continue
+		}
+		if c.node.Line != c.linha || c.node.Column != c.coluna {
+			t.Errorf("%s: posição = %d:%d, esperado %d:%d", c.nome, c.node.Line, c.node.Column, c.linha, c.coluna)
+		}
+	}
+
+	if valor, _ := texto(valorDoMapa(doc, "x-texto")); valor != "aéb" {
+		t.Errorf("escape \\u decodificado = %q, esperado aéb", valor)
+	}
+	if valor, _ := texto(valorDoMapa(doc, "x-emoji")); valor != "😀" {
+		t.Errorf("par substituto decodificado = %q, esperado 😀", valor)
+	}
+}
+
+func TestJSONParaNodeErroDeSintaxe(t *testing.T) {
+	_, err := jsonParaNode([]byte("{\n  \"a\": [1,\n    x]\n}\n"))
+	if err == nil {
+		t.Fatal("esperado um erro de sintaxe")
+	}
+	if got, want := err.Error(), "linha 3, coluna 5"; !strings.Contains(got, want) {
+		t.Errorf("erro = %q, esperado a posição %q", got, want)
+	}
+}
+
+func TestCodificarDocumentoIdaEVolta(t *testing.T) {
+	casos := []struct {
+		nome     string
+		conteudo string
+		literal  bool // o bloco literal deve ser mantido na saída
+	}{
+		{"bloco literal com espaços no fim das linhas", "info:\n  description: |\n    linha com espaços  \n    segunda linha\n", true},
+		{"escalar com o marcador", "info:\n  title: \"a\\ue000b\"\n  description: |\n    linha com espaços  \n    segunda linha\n", false},
+	}
+	for _, c := range casos {
+		t.Run(c.nome, func(t *testing.T) {
+			rootNode, _, err := lerDocumento([]byte(c.conteudo))
+			if err != nil {
+				t.Fatalf("erro ao ler o documento: %v", err)
+			}
+			var original interface{}
+			if err := rootNode.Decode(&original); err != nil {
+				t.Fatal(err)
+			}
+
+			saida, err := codificarDocumento(rootNode, formatoYAML, 2)
+			if err != nil {
+				t.Fatalf("erro ao codificar: %v", err)
+			}
+			var relido interface{}
+			if err := yaml.Unmarshal(saida, &relido); err != nil {
+				t.Fatalf("saída inválida: %v\n%s", err, saida)
+			}
+			if !reflect.DeepEqual(original, relido) {
+				t.Errorf("valores alterados na ida e volta:\n%v\n%v\nsaída:\n%s", original, relido, saida)
+			}
+			if c.literal && !strings.Contains(string(saida), "description: |\n    linha com espaços  \n") {
+				t.Errorf("o bloco literal não foi mantido:\n%s", saida)
+			}
+
+			// o documento original não é alterado pela codificação
+			var depois interface{}
+			if err := rootNode.Decode(&depois); err != nil {
+				t.Fatal(err)
+			}
+			if !reflect.DeepEqual(original, depois) {
+				t.Errorf("o documento foi alterado pela codificação: %v", depois)
+			}
+		})
+	}
+}
+
+func TestEscalarJSONMantemNumeros(t *testing.T) {
+	mapa := mapaDeTeste(t, "exp: 1.5e3\nzero: -0.0\ninteiro: 10\nhexa: 0x1F\ndecimal: .5\nbooleano: true\nnulo: ~\n")
+	esperados := map[string]string{
+		"exp":      "1.5e3",
+		"zero":     "-0.0",
+		"inteiro":  "10",
+		"hexa":     "31",
+		"decimal":  "0.5",
+		"booleano": "true",
+		"nulo":     "null",
+	}
+	for chave, esperado := range esperados {
+		if got := string(escalarJSON(valorDoMapa(mapa, chave))); got != esperado {
+			t.Errorf("escalarJSON(%s) = %s, esperado %s", chave, got, esperado)
+		}
+	}
+}
@@ -0,0 +1,150 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/dlclark/regexp2"
+	"gopkg.in/yaml.v3"
+)
+
+// amostrasFormato são valores de teste de um format: os válidos devem ser aceitos pelo pattern e os
+// inválidos, rejeitados
+type amostrasFormato struct {
+	validas   []string
+	invalidas []string
+}
+
+// dias de cada mês em um ano que não é bissexto
+var diasDoMes = [12]int{31, 28, 31, 30, 31, 30, 31, 31, 30, 31, 30, 31}
+
+// Função para gerar as amostras de date nos limites do calendário: o primeiro e o último dia de cada mês,
+// o 29 de fevereiro de anos bissextos e o dia seguinte ao último de cada mês. O 29 de fevereiro de anos que
+// não são bissextos não é testado, pois exigiria do pattern o cálculo do ano bissexto
+func amostrasDeDate() amostrasFormato {
+	amostras := amostrasFormato{
+		validas:   []string{"2020-02-29", "2000-02-29", "1999-12-31"},
+		invalidas: []string{"2021-00-10", "2021-13-01", "2021-05-00", "2021-5-21", "2021-05-1", "21-05-21", "2021-05-21T08:30:00Z"},
+	}
+	for i, dias := range diasDoMes {
+		mes := i + 1
+		amostras.validas = append(amostras.validas, fmt.Sprintf("2021-%02d-01", mes), fmt.Sprintf("2021-%02d-%02d", mes, dias))
+		if mes == 2 {
+			dias = 29
+		}
+		amostras.invalidas = append(amostras.invalidas, fmt.Sprintf("2021-%02d-%02d", mes, dias+1))
+	}
+	return amostras
+}
+
+// Função para gerar as amostras de date-time em UTC: as datas limite de amostrasDeDate com os horários
+// limite do dia, frações de segundo e horários fora dos limites
+func amostrasDeDateTime() amostrasFormato {
+	datas := amostrasDeDate()
+	var amostras amostrasFormato
+	for _, data := range datas.validas {
+		amostras.validas = append(amostras.validas, data+"T00:00:00Z", data+"T23:59:59Z")
+	}
+	for _, fracao := range []string{".0", ".5", ".000", ".123456"} {
+		amostras.validas = append(amostras.validas, "2021-05-21T08:30:00"+fracao+"Z")
+	}
+	for _, data := range datas.invalidas {
+		if !strings.Contains(data, "T") {
+			amostras.invalidas = append(amostras.invalidas, data+"T08:30:00Z")
+		}
+	}
+	for _, horario := range []string{"24:00:00", "08:60:00", "08:30:61", "8:30:00", "08:30", "08:30:00."} {
+		amostras.invalidas = append(amostras.invalidas, "2021-05-21T"+horario+"Z")
+	}
+	amostras.invalidas = append(amostras.invalidas, "2021-05-21", "2021-05-21T08:30:00")
+	return amostras
+}
+
+// amostras dos formats verificados; as de date e date-time são geradas nos limites do calendário e do relógio,
+// e os valores válidos de date-time estão em UTC, como exigido no Open Finance
+var amostrasDeFormatos = map[string]amostrasFormato{
+	"date":      amostrasDeDate(),
+	"date-time": amostrasDeDateTime(),
+	"uuid": {
+		validas: []string{"123e4567-e89b-12d3-a456-426614174000", "00000000-0000-0000-0000-000000000000"},
+		invalidas: []string{"123e4567e89b12d3a456426614174000", "123e4567-e89b-12d3-a456-42661417400g",
+			"123e4567-e89b-12d3-a456-4266141740000"},
+	},
+	"uri": {
+		validas:   []string{"https://api.banco.com.br/open-banking/api/v1/resource", "http://example.com"},
+		invalidas: []string{"texto com espaços"},
+	},
+	"email": {
+		validas:   []string{"nome@banco.com.br", "nome.sobrenome+tag@example.com"},
+		invalidas: []string{"nome.banco.com.br", "nome@", "@banco.com.br"},
+	},
+}
+
+// date-times com fuso diferente de UTC, que devem ser rejeitados pelos patterns no Open Finance
+var dateTimesForaDeUTC = []string{"2021-05-21T08:30:00-03:00", "2021-05-21T08:30:00+00:00"}
+
+// Função para compilar o pattern de um schema como ECMA-262; o pattern vazio indica que o schema não tem pattern
+func compilarPattern(schema *yaml.Node) (string, *regexp2.Regexp, error) {
+	pattern, ok := texto(valorDoMapa(schema, "pattern"))
+	if !ok {
+		return "", nil, nil
+	}
+	re, err := compilarECMA(pattern)
+	return pattern, re, err
+}
+
+// Função para validar se o pattern de um schema com format aceita os valores válidos do format e rejeita os inválidos
+func validarFormatoPattern(schema *yaml.Node, validationErrors *[]error, p *percurso, r regra, formatos []string, campo string) []error {
+	formato, _ := texto(valorDoMapa(schema, "format"))
+	amostras, conhecido := amostrasDeFormatos[formato]
+	pattern, re, err := compilarPattern(schema)
+	switch {
+	case pattern == "" || !conhecido || !contemTexto(formatos, formato):
+	case err != nil:
+		*validationErrors = append(*validationErrors, r.findingDetalhado(campo,
+			fmt.Sprintf("O pattern %q não é uma expressão regular ECMA-262 válida e não pode ser comparado ao format %s: %v.", pattern, formato, err)))
+	default:
+		for _, valor := range amostras.validas {
+			if !aceita(re, valor) {
+				*validationErrors = append(*validationErrors, r.findingDetalhado(campo,
+					fmt.Sprintf("O pattern %q não aceita o valor válido %q para o format %s.", pattern, valor, formato)))
+				break
+			}
+		}
+		for _, valor := range amostras.invalidas {
+			if aceitaComCerteza(re, valor) {
+				*validationErrors = append(*validationErrors, r.findingDetalhado(campo,
+					fmt.Sprintf("O pattern %q aceita o valor inválido %q para o format %s.", pattern, valor, formato)))
+				break
+			}
+		}
+	}
+	visitarFilhos(schema, p, campo, func(sub *yaml.Node, campo string) {
+		*validationErrors = validarFormatoPattern(sub, validationErrors, p, r, formatos, campo)
+	})
+	return *validationErrors
+}
+
+// Função para validar se os campos date-time exigem UTC: o pattern deve rejeitar outros fusos e o exemplo
+// deve terminar em Z
+func validarDateTimeUTC(schema *yaml.Node, validationErrors *[]error, p *percurso, r regra, campo string) []error {
+	if formato, _ := texto(valorDoMapa(schema, "format")); formato == "date-time" {
+		if pattern, re, err := compilarPattern(schema); pattern != "" && err == nil {
+			for _, valor := range dateTimesForaDeUTC {
+				if aceitaComCerteza(re, valor) {
+					*validationErrors = append(*validationErrors, r.findingDetalhado(campo,
+						fmt.Sprintf("O pattern %q aceita o date-time fora de UTC %q.", pattern, valor)))
+					break
+				}
+			}
+		}
+		if exemplo, ok := texto(valorDoMapa(schema, "example")); ok && !strings.HasSuffix(exemplo, "Z") {
+			*validationErrors = append(*validationErrors, r.findingDetalhado(campo,
+				fmt.Sprintf("O exemplo %q deve estar em UTC, com o sufixo Z.", exemplo)))
+		}
+	}
+	visitarFilhos(schema, p, campo, func(sub *yaml.Node, campo string) {
+		*validationErrors = validarDateTimeUTC(sub, validationErrors, p, r, campo)
+	})
+	return *validationErrors
+}
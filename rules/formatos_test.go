@@ -0,0 +1,45 @@
+package main
+
+import (
+	"errors"
+	"strings"
+	"testing"
+)
+
+func TestValidarFormatoPattern(t *testing.T) {
+	r := regra{nome: "format-pattern-consistency", severity: "warn", dados: map[string]interface{}{"description": "teste"}}
+	casos := []struct {
+		nome     string
+		schema   string
+		esperado string // trecho do finding esperado; vazio quando o pattern é consistente
+	}{
+		{"date consistente", `{type: string, format: date, pattern: '^\d{4}-(0[1-9]|1[0-2])-(0[1-9]|1\d|2[0-8]|29|(?<!02-)30|(?<=(0[13578]|1[02])-)31)$'}`, ""},
+		{"date que aceita 30 de fevereiro", `{type: string, format: date, pattern: '^\d{4}-(0[1-9]|1[0-2])-(0[1-9]|[12]\d|3[01])$'}`, `aceita o valor inválido "2021-02-30"`},
+		{"date que rejeita o 29 de fevereiro", `{type: string, format: date, pattern: '^\d{4}-(0[1-9]|1[0-2])-(0[1-9]|1\d|2[0-8]|3[01])$'}`, `não aceita o valor válido "2020-02-29"`},
+		{"date-time sem frações de segundo", `{type: string, format: date-time, pattern: '^\d{4}-\d{2}-\d{2}T\d{2}:\d{2}:\d{2}Z$'}`, `não aceita o valor válido "2021-05-21T08:30:00.0Z"`},
+		{"date-time que aceita 24h", `{type: string, format: date-time, pattern: '^\d{4}-(0[1-9]|1[0-2])-(0[1-9]|1\d|2[0-8]|29|(?<!02-)30|(?<=(0[13578]|1[02])-)31)T([01]\d|2[0-4]):[0-5]\d:[0-5]\d(\.\d+)?Z$'}`, `aceita o valor inválido "2021-05-21T24:00:00Z"`},
+		{"uuid com lookahead", `{type: string, format: uuid, pattern: '^(?=.{36}$)[0-9a-f]{8}(-[0-9a-f]{4}){3}-[0-9a-f]{12}$'}`, ""},
+		{"pattern inválido", `{type: string, format: uuid, pattern: '^([0-9a-f]{8}$'}`, "não é uma expressão regular ECMA-262 válida"},
+	}
+	for _, c := range casos {
+		t.Run(c.nome, func(t *testing.T) {
+			schema := mapaDeTeste(t, c.schema)
+			var validationErrors []error
+			validarFormatoPattern(schema, &validationErrors, novoPercurso(nil, nil), r, []string{"date", "date-time", "uuid"}, "#/schema")
+
+			var descricoes []string
+			for _, e := range validationErrors {
+				var f *finding
+				if errors.As(e, &f) {
+					descricoes = append(descricoes, f.descricao)
+				}
+			}
+			if c.esperado == "" && len(descricoes) > 0 {
+				t.Errorf("nenhum finding esperado, encontrado %v", descricoes)
+			}
+			if c.esperado != "" && !strings.Contains(strings.Join(descricoes, "\n"), c.esperado) {
+				t.Errorf("esperado um finding com %q, encontrado %v", c.esperado, descricoes)
+			}
+		})
+	}
+}
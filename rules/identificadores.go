@@ -0,0 +1,166 @@
+package main
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// identificador é a definição canônica de um identificador brasileiro (CPF, CNPJ, ISPB, agência, conta)
+type identificador struct {
+	nome         string
+	propriedades []string       // nomes de propriedades que sempre são este identificador
+	descricao    *regexp.Regexp // identifica as propriedades de nome genérico pela descrição
+	pattern      string
+	minLength    int // 0 quando não exigido
+	maxLength    int
+	digitos      string // algoritmo do dígito verificador dos exemplos: cpf, cnpj ou cpf-cnpj
+}
+
+// Função para ler os identificadores das opções da regra; a ordem importa, pois o primeiro que corresponde é usado
+func identificadoresDasOpcoes(opcoes map[string]interface{}) ([]identificador, error) {
+	lista, _ := opcoes["identifiers"].([]interface{})
+	var identificadores []identificador
+	for _, item := range lista {
+		dados, _ := item.(map[string]interface{})
+		id := identificador{propriedades: textosDaOpcao(dados["properties"])}
+		id.nome, _ = dados["name"].(string)
+		id.pattern, _ = dados["pattern"].(string)
+		id.minLength, _ = dados["minLength"].(int)
+		id.maxLength, _ = dados["maxLength"].(int)
+		id.digitos, _ = dados["checksum"].(string)
+		if descricao, ok := dados["description"].(string); ok {
+			re, err := regexp.Compile("(?i)" + descricao)
+			if err != nil {
+				return nil, fmt.Errorf("regex de descrição inválido para %s: %v", id.nome, err)
+			}
+			id.descricao = re
+		}
+		if _, err := regexp.Compile(id.pattern); err != nil {
+			return nil, fmt.Errorf("pattern canônico inválido para %s: %v", id.nome, err)
+		}
+		identificadores = append(identificadores, id)
+	}
+	return identificadores, nil
+}
+
+// Função para reconhecer o identificador de uma propriedade pelo nome ou, nas de nome genérico, pela descrição
+func reconhecerIdentificador(identificadores []identificador, genericas []string, nome string, schema *yaml.Node) (identificador, bool) {
+	descricao, _ := texto(valorDoMapa(schema, "description"))
+	for _, id := range identificadores {
+		for _, propriedade := range id.propriedades {
+			if strings.EqualFold(propriedade, nome) {
+				return id, true
+			}
+		}
+	}
+	for _, generica := range genericas {
+		if !strings.EqualFold(generica, nome) {
+			continue
+		}
+		for _, id := range identificadores {
+			if id.descricao != nil && id.descricao.MatchString(descricao) {
+				return id, true
+			}
+		}
+	}
+	return identificador{}, false
+}
+
+// Função para calcular um dígito verificador módulo 11 com os pesos informados
+func digitoModulo11(digitos string, pesos []int) byte {
+	soma := 0
+	for i, peso := range pesos {
+		soma += int(digitos[i]-'0') * peso
+	}
+	resto := soma % 11
+	if resto < 2 {
+		return '0'
+	}
+	return byte('0' + 11 - resto)
+}
+
+// Função para verificar se todos os dígitos são iguais, caso que passa no cálculo mas não é um documento válido
+func digitosRepetidos(digitos string) bool {
+	return strings.Count(digitos, digitos[:1]) == len(digitos)
+}
+
+// Função para validar os dígitos verificadores de um CPF
+func cpfValido(cpf string) bool {
+	if !regexp.MustCompile(`^\d{11}$`).MatchString(cpf) || digitosRepetidos(cpf) {
+		return false
+	}
+	return cpf[9] == digitoModulo11(cpf, []int{10, 9, 8, 7, 6, 5, 4, 3, 2}) &&
+		cpf[10] == digitoModulo11(cpf, []int{11, 10, 9, 8, 7, 6, 5, 4, 3, 2})
+}
+
+// Função para validar os dígitos verificadores de um CNPJ
+func cnpjValido(cnpj string) bool {
+	if !regexp.MustCompile(`^\d{14}$`).MatchString(cnpj) || digitosRepetidos(cnpj) {
+		return false
+	}
+	return cnpj[12] == digitoModulo11(cnpj, []int{5, 4, 3, 2, 9, 8, 7, 6, 5, 4, 3, 2}) &&
+		cnpj[13] == digitoModulo11(cnpj, []int{6, 5, 4, 3, 2, 9, 8, 7, 6, 5, 4, 3, 2})
+}
+
+// Função para validar os dígitos verificadores do exemplo conforme o algoritmo do identificador
+func digitosVerificadoresValidos(algoritmo, valor string) bool {
+	switch algoritmo {
+	case "cpf":
+		return cpfValido(valor)
+	case "cnpj":
+		return cnpjValido(valor)
+	case "cpf-cnpj":
+		return cpfValido(valor) || cnpjValido(valor)
+	}
+	return true
+}
+
+// Função para obter o nome da propriedade a partir do último segmento do JSON pointer
+func nomeDoCampo(campo string) string {
+	nome := campo[strings.LastIndex(campo, "/")+1:]
+	return strings.NewReplacer("~1", "/", "~0", "~").Replace(nome)
+}
+
+// Função para validar se os campos reconhecidos como identificadores seguem a definição canônica
+func validarIdentificador(schema *yaml.Node, validationErrors *[]error, p *percurso, r regra, identificadores []identificador, genericas []string, campo string) []error {
+	if id, ok := reconhecerIdentificador(identificadores, genericas, nomeDoCampo(campo), schema); ok && temTipo(schema, "string") {
+		var problemas []string
+		if pattern, definido := texto(valorDoMapa(schema, "pattern")); !definido {
+			problemas = append(problemas, fmt.Sprintf("deve ter o pattern %q", id.pattern))
+		} else if pattern != id.pattern {
+			problemas = append(problemas, fmt.Sprintf("deve ter o pattern %q, encontrado %q", id.pattern, pattern))
+		}
+		limites := []struct {
+			chave string
+			valor int
+		}{{"minLength", id.minLength}, {"maxLength", id.maxLength}}
+		for _, limite := range limites {
+			if limite.valor == 0 {
+				continue
+			}
+			if atual, definido := inteiro(valorDoMapa(schema, limite.chave)); !definido {
+				problemas = append(problemas, fmt.Sprintf("deve ter %s %d", limite.chave, limite.valor))
+			} else if atual != limite.valor {
+				problemas = append(problemas, fmt.Sprintf("deve ter %s %d, encontrado %d", limite.chave, limite.valor, atual))
+			}
+		}
+		if exemplo, definido := texto(valorDoMapa(schema, "example")); definido {
+			if !regexp.MustCompile(id.pattern).MatchString(exemplo) {
+				problemas = append(problemas, fmt.Sprintf("o exemplo %q não corresponde ao pattern %q", exemplo, id.pattern))
+			} else if !digitosVerificadoresValidos(id.digitos, exemplo) {
+				problemas = append(problemas, fmt.Sprintf("o exemplo %q tem dígitos verificadores inválidos", exemplo))
+			}
+		}
+		if len(problemas) > 0 {
+			*validationErrors = append(*validationErrors, r.findingDetalhado(campo,
+				fmt.Sprintf("No campo %s (%s): %s.", nomeDoCampo(campo), id.nome, strings.Join(problemas, "; "))))
+		}
+	}
+	visitarFilhos(schema, p, campo, func(sub *yaml.Node, campo string) {
+		*validationErrors = validarIdentificador(sub, validationErrors, p, r, identificadores, genericas, campo)
+	})
+	return *validationErrors
+}
@@ -0,0 +1,41 @@
+package main
+
+import "testing"
+
+func TestDigitosVerificadores(t *testing.T) {
+	casos := []struct {
+		algoritmo, valor string
+		valido           bool
+	}{
+		{"cpf", "52998224725", true},
+		{"cpf", "11144477735", true},
+		{"cpf", "12345678909", true},
+		{"cpf", "52998224724", false}, // segundo dígito errado
+		{"cpf", "52998224715", false}, // primeiro dígito errado
+		{"cpf", "12345678900", false},
+		{"cpf", "11111111111", false}, // dígitos repetidos passam no módulo 11, mas não são CPFs
+		{"cpf", "00000000000", false},
+		{"cpf", "529.982.247-25", false},
+		{"cpf", "5299822472", false},
+		{"cpf", "", false},
+		{"cnpj", "11222333000181", true},
+		{"cnpj", "11444777000161", true},
+		{"cnpj", "00000000000191", true},
+		{"cnpj", "11222333000182", false},
+		{"cnpj", "11222333000171", false},
+		{"cnpj", "00000000000000", false},
+		{"cnpj", "11111111111111", false},
+		{"cnpj", "11.222.333/0001-81", false},
+		{"cnpj", "52998224725", false},
+		{"cpf-cnpj", "52998224725", true},
+		{"cpf-cnpj", "11222333000181", true},
+		{"cpf-cnpj", "11222333000182", false},
+		{"cpf-cnpj", "99999999999", false},
+		{"", "qualquer valor", true}, // sem algoritmo, o exemplo não é verificado
+	}
+	for _, c := range casos {
+		if got := digitosVerificadoresValidos(c.algoritmo, c.valor); got != c.valido {
+			t.Errorf("digitosVerificadoresValidos(%q, %q) = %v, esperado %v", c.algoritmo, c.valor, got, c.valido)
+		}
+	}
+}
@@ -0,0 +1,114 @@
+package main
+
+import (
+	"fmt"
+	"path"
+	"regexp"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// convencaoMonetaria é a convenção dos campos de valor ou de moeda: reconhecidos pelo nome, devem ser strings
+// com um pattern que aceite os valores válidos e rejeite os inválidos
+type convencaoMonetaria struct {
+	nome         string
+	propriedades []string // nomes ou padrões com * (ex.: *Amount)
+	pattern      string
+	maxLength    int
+	validos      []string
+	invalidos    []string
+}
+
+// Função para ler uma convenção das opções da regra
+func convencaoDasOpcoes(nome string, valor interface{}) convencaoMonetaria {
+	dados, _ := valor.(map[string]interface{})
+	c := convencaoMonetaria{
+		nome:         nome,
+		propriedades: textosDaOpcao(dados["properties"]),
+		validos:      textosDaOpcao(dados["valid"]),
+		invalidos:    textosDaOpcao(dados["invalid"]),
+	}
+	c.pattern, _ = dados["pattern"].(string)
+	c.maxLength, _ = dados["maxLength"].(int)
+	return c
+}
+
+// Função para verificar se o nome da propriedade corresponde à convenção
+func (c convencaoMonetaria) aplicaA(nome string) bool {
+	for _, padrao := range c.propriedades {
+		if ok, _ := path.Match(padrao, nome); ok {
+			return true
+		}
+	}
+	return false
+}
+
+// Função para listar o que o schema de um campo descumpre da convenção
+func (c convencaoMonetaria) problemas(schema *yaml.Node) []string {
+	if !temTipo(schema, "string") {
+		tipo, _ := texto(valorDoMapa(schema, "type"))
+		if temTipo(schema, "number") || temTipo(schema, "integer") {
+			return []string{fmt.Sprintf("não deve usar o tipo %s para dinheiro; use string com o pattern %q", tipo, c.pattern)}
+		}
+		return []string{fmt.Sprintf("deve ser do tipo string, encontrado %q", tipo)}
+	}
+	var problemas []string
+	if pattern, re, err := compilarPattern(schema); pattern == "" {
+		problemas = append(problemas, fmt.Sprintf("deve ter o pattern %q", c.pattern))
+	} else if err == nil {
+		for _, valor := range c.validos {
+			if !aceita(re, valor) {
+				problemas = append(problemas, fmt.Sprintf("o pattern %q não aceita %q; use o pattern %q", pattern, valor, c.pattern))
+				break
+			}
+		}
+		for _, valor := range c.invalidos {
+			if aceitaComCerteza(re, valor) {
+				problemas = append(problemas, fmt.Sprintf("o pattern %q aceita %q; use o pattern %q", pattern, valor, c.pattern))
+				break
+			}
+		}
+	}
+	if c.maxLength > 0 {
+		if atual, definido := inteiro(valorDoMapa(schema, "maxLength")); !definido {
+			problemas = append(problemas, fmt.Sprintf("deve ter maxLength %d", c.maxLength))
+		} else if atual != c.maxLength {
+			problemas = append(problemas, fmt.Sprintf("deve ter maxLength %d, encontrado %d", c.maxLength, atual))
+		}
+	}
+	return problemas
+}
+
+// Função para validar os campos de valor e de moeda conforme as convenções da regra
+func validarCamposMonetarios(schema *yaml.Node, validationErrors *[]error, p *percurso, r regra, convencoes []convencaoMonetaria, campo string) []error {
+	nome := nomeDoCampo(campo)
+	for _, c := range convencoes {
+		if !c.aplicaA(nome) {
+			continue
+		}
+		if problemas := c.problemas(schema); len(problemas) > 0 {
+			*validationErrors = append(*validationErrors, r.findingDetalhado(campo,
+				fmt.Sprintf("No campo %s (%s): %s.", nome, c.nome, strings.Join(problemas, "; "))))
+		}
+		break
+	}
+	visitarFilhos(schema, p, campo, func(sub *yaml.Node, campo string) {
+		*validationErrors = validarCamposMonetarios(sub, validationErrors, p, r, convencoes, campo)
+	})
+	return *validationErrors
+}
+
+// Função para ler as convenções de valor e de moeda das opções da regra, verificando os patterns padrão
+func convencoesMonetarias(opcoes map[string]interface{}) ([]convencaoMonetaria, error) {
+	convencoes := []convencaoMonetaria{
+		convencaoDasOpcoes("valor", opcoes["amounts"]),
+		convencaoDasOpcoes("moeda", opcoes["currencies"]),
+	}
+	for _, c := range convencoes {
+		if _, err := regexp.Compile(c.pattern); err != nil {
+			return nil, fmt.Errorf("pattern padrão inválido para %s: %v", c.nome, err)
+		}
+	}
+	return convencoes, nil
+}
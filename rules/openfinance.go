@@ -0,0 +1,163 @@
+package main
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/pb33f/libopenapi/index"
+	"gopkg.in/yaml.v3"
+)
+
+// Função para obter uma lista de textos das opções da regra
+func textosDaOpcao(valor interface{}) []string {
+	lista, _ := valor.([]interface{})
+	var textos []string
+	for _, item := range lista {
+		textos = append(textos, fmt.Sprint(item))
+	}
+	return textos
+}
+
+// Função para obter as chaves de um mapa das opções da regra em ordem alfabética, para a saída ser estável
+func chavesDaOpcao(valor interface{}) []string {
+	mapa, _ := valor.(map[string]interface{})
+	var chaves []string
+	for chave := range mapa {
+		chaves = append(chaves, chave)
+	}
+	sort.Strings(chaves)
+	return chaves
+}
+
+// Função para verificar se uma lista de declarações contém o nome, sem diferenciar maiúsculas de minúsculas
+func buscarDeclaracao(declaracoes []declaracao, nome string) (declaracao, bool) {
+	for _, d := range declaracoes {
+		if strings.EqualFold(d.nome, nome) {
+			return d, true
+		}
+	}
+	return declaracao{}, false
+}
+
+// Função para validar se cada operação declara os headers de requisição exigidos pela regra
+func validarHeadersRequisicao(doc *yaml.Node, idx *index.SpecIndex, validationErrors *[]error, r regra) []error {
+	exigidos := textosDaOpcao(r.opcoes()["headers"])
+	for _, op := range listarOperacoes(doc) {
+		headers := headersDaOperacao(idx, op)
+		for _, nome := range exigidos {
+			if _, ok := buscarDeclaracao(headers, nome); !ok {
+				*validationErrors = append(*validationErrors, r.findingDetalhado(op.ponteiro,
+					fmt.Sprintf("O header %s não é aceito por %s %s.", nome, strings.ToUpper(op.metodo), op.path)))
+			}
+		}
+	}
+	return *validationErrors
+}
+
+// Função para validar se cada response declara os headers exigidos para o seu status code
+func validarHeadersResposta(doc *yaml.Node, idx *index.SpecIndex, validationErrors *[]error, r regra) []error {
+	exigidos, _ := r.opcoes()["headers"].(map[string]interface{})
+	nomes := chavesDaOpcao(exigidos)
+	for _, op := range listarOperacoes(doc) {
+		for _, resposta := range respostasDaOperacao(idx, op) {
+			headers := headersDaResposta(idx, resposta)
+			for _, nome := range nomes {
+				aplica := false
+				for _, padrao := range textosDaOpcao(exigidos[nome]) {
+					aplica = aplica || statusCorresponde(resposta.nome, padrao)
+				}
+				if _, ok := buscarDeclaracao(headers, nome); aplica && !ok {
+					*validationErrors = append(*validationErrors, r.findingDetalhado(op.ponteiro+"/responses/"+escaparPonteiro(resposta.nome),
+						fmt.Sprintf("O header %s não é retornado pela response %s de %s %s.", nome, resposta.nome, strings.ToUpper(op.metodo), op.path)))
+				}
+			}
+		}
+	}
+	return *validationErrors
+}
+
+// Função para validar o schema dos headers padrão, em parâmetros e em responses; cada declaração é
+// verificada uma única vez, mesmo quando reutilizada por $ref em várias operações
+func validarSchemasHeaders(doc *yaml.Node, idx *index.SpecIndex, validationErrors *[]error, r regra) []error {
+	esperados, _ := r.opcoes()["headers"].(map[string]interface{})
+	vistos := make(map[string]bool)
+	validar := func(header declaracao) {
+		if vistos[header.campo] {
+			return
+		}
+		vistos[header.campo] = true
+		var nome string
+		for _, candidato := range chavesDaOpcao(esperados) {
+			if strings.EqualFold(candidato, header.nome) {
+				nome = candidato
+			}
+		}
+		if nome == "" {
+			return
+		}
+		esperado, _ := esperados[nome].(map[string]interface{})
+
+		schema := schemaDaDeclaracao(idx, header.node)
+		if schema == nil {
+			*validationErrors = append(*validationErrors, r.findingDetalhado(header.campo, fmt.Sprintf("O header %s não declara schema.", header.nome)))
+			return
+		}
+		if padrao, ok := esperado["pattern"].(string); ok {
+			atual, definido := texto(valorDoMapa(schema, "pattern"))
+			if !definido {
+				*validationErrors = append(*validationErrors, r.findingDetalhado(header.campo,
+					fmt.Sprintf("O header %s deve ter o pattern %q.", header.nome, padrao)))
+			} else if atual != padrao {
+				*validationErrors = append(*validationErrors, r.findingDetalhado(header.campo,
+					fmt.Sprintf("O header %s deve ter o pattern %q, encontrado %q.", header.nome, padrao, atual)))
+			}
+		}
+		if limite, ok := esperado["maxLength"].(int); ok {
+			maxLength, definido := inteiro(valorDoMapa(schema, "maxLength"))
+			switch {
+			case !definido:
+				*validationErrors = append(*validationErrors, r.findingDetalhado(header.campo,
+					fmt.Sprintf("O header %s deve definir maxLength (no máximo %d).", header.nome, limite)))
+			case maxLength > limite:
+				*validationErrors = append(*validationErrors, r.findingDetalhado(header.campo,
+					fmt.Sprintf("O header %s tem maxLength %d, acima do limite de %d.", header.nome, maxLength, limite)))
+			}
+		}
+	}
+
+	for _, op := range listarOperacoes(doc) {
+		for _, header := range headersDaOperacao(idx, op) {
+			validar(header)
+		}
+		for _, resposta := range respostasDaOperacao(idx, op) {
+			for _, header := range headersDaResposta(idx, resposta) {
+				validar(header)
+			}
+		}
+	}
+	return *validationErrors
+}
+
+// Função para validar se as operações dos métodos da regra declaram o header de idempotência como obrigatório
+func validarChaveIdempotencia(doc *yaml.Node, idx *index.SpecIndex, validationErrors *[]error, r regra) []error {
+	opcoes := r.opcoes()
+	nome, _ := opcoes["header"].(string)
+	metodos := textosDaOpcao(opcoes["methods"])
+	for _, op := range listarOperacoes(doc) {
+		if !contemTexto(metodos, op.metodo) {
+			continue
+		}
+		header, ok := buscarDeclaracao(headersDaOperacao(idx, op), nome)
+		if !ok {
+			*validationErrors = append(*validationErrors, r.findingDetalhado(op.ponteiro,
+				fmt.Sprintf("%s %s não declara o header %s.", strings.ToUpper(op.metodo), op.path, nome)))
+			continue
+		}
+		if obrigatorio, _ := booleano(valorDoMapa(header.node, "required")); !obrigatorio {
+			*validationErrors = append(*validationErrors, r.findingDetalhado(header.campo,
+				fmt.Sprintf("O header %s deve ser obrigatório (required: true) em %s %s.", nome, strings.ToUpper(op.metodo), op.path)))
+		}
+	}
+	return *validationErrors
+}
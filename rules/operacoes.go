@@ -0,0 +1,173 @@
+package main
+
+import (
+	"strconv"
+	"strings"
+
+	"github.com/pb33f/libopenapi/index"
+	"gopkg.in/yaml.v3"
+)
+
+// operacao representa uma operação (método de um path item) do documento
+type operacao struct {
+	ponteiro string // JSON pointer da operação, ex.: #/paths/~1pix~1payments/post
+	path     string
+	metodo   string
+	pathItem *yaml.Node
+	node     *yaml.Node
+}
+
+// declaracao é um parâmetro, header ou response já sem $ref, com o JSON pointer de onde foi declarado
+type declaracao struct {
+	campo string
+	nome  string
+	node  *yaml.Node
+}
+
+// Função para listar as operações dos paths, na ordem do documento
+func listarOperacoes(doc *yaml.Node) []operacao {
+	var operacoes []operacao
+	for _, par := range paresDoMapa(valorDoMapa(doc, "paths")) {
+		pathItem := resolverAlias(par.valor)
+		for _, metodo := range metodosHTTP {
+			if node := valorDoMapa(pathItem, metodo); node != nil {
+				operacoes = append(operacoes, operacao{
+					ponteiro: "#/paths/" + escaparPonteiro(par.chave.Value) + "/" + metodo,
+					path:     par.chave.Value,
+					metodo:   metodo,
+					pathItem: pathItem,
+					node:     node,
+				})
+			}
+		}
+	}
+	return operacoes
+}
+
+// Função para seguir os $refs de um nó até o conteúdo usando o índice; retorna também o último $ref local
+// seguido, que é a localização da declaração, ou vazio se o nó não era um $ref
+func derreferenciar(idx *index.SpecIndex, node *yaml.Node) (*yaml.Node, string) {
+	node = resolverAlias(node)
+	origem := ""
+	vistos := make(map[*yaml.Node]bool)
+	for idx != nil && node != nil && !vistos[node] {
+		ref, ok := texto(valorDoMapa(node, "$ref"))
+		if !ok {
+			break
+		}
+		vistos[node] = true
+		encontrado, _ := idx.SearchIndexForReference(ref)
+		if encontrado == nil || encontrado.Node == nil {
+			// referências não encontradas já são reportadas pelo índice
+			return nil, origem
+		}
+		if strings.HasPrefix(ref, "#/") {
+			origem = ref
+		}
+		node = resolverAlias(encontrado.Node)
+	}
+	return node, origem
+}
+
+// Função para listar os parâmetros efetivos da operação: os do path item mais os da operação, que
+// substituem os do path item com o mesmo nome e localização
+func parametrosDaOperacao(idx *index.SpecIndex, op operacao) []declaracao {
+	pathPonteiro := op.ponteiro[:strings.LastIndex(op.ponteiro, "/")]
+	var parametros []declaracao
+	posicoes := make(map[string]int)
+	adicionar := func(lista *yaml.Node, ponteiro string) {
+		for i, item := range itensDaLista(lista) {
+			node, origem := derreferenciar(idx, item)
+			if node == nil {
+				continue
+			}
+			campo := ponteiro + "/parameters/" + strconv.Itoa(i)
+			if origem != "" {
+				campo = origem
+			}
+			nome, _ := texto(valorDoMapa(node, "name"))
+			local, _ := texto(valorDoMapa(node, "in"))
+			chave := strings.ToLower(local) + ":" + nome
+			if local == "header" {
+				// nomes de headers não diferenciam maiúsculas de minúsculas
+				chave = strings.ToLower(chave)
+			}
+			if i, ok := posicoes[chave]; ok {
+				parametros[i] = declaracao{campo, nome, node}
+				continue
+			}
+			posicoes[chave] = len(parametros)
+			parametros = append(parametros, declaracao{campo, nome, node})
+		}
+	}
+	adicionar(valorDoMapa(op.pathItem, "parameters"), pathPonteiro)
+	adicionar(valorDoMapa(op.node, "parameters"), op.ponteiro)
+	return parametros
+}
+
+// Função para listar os headers da operação declarados como parâmetros
+func headersDaOperacao(idx *index.SpecIndex, op operacao) []declaracao {
+	var headers []declaracao
+	for _, parametro := range parametrosDaOperacao(idx, op) {
+		if local, _ := texto(valorDoMapa(parametro.node, "in")); strings.EqualFold(local, "header") {
+			headers = append(headers, parametro)
+		}
+	}
+	return headers
+}
+
+// Função para listar as responses da operação; o nome da declaração é o status code
+func respostasDaOperacao(idx *index.SpecIndex, op operacao) []declaracao {
+	var respostas []declaracao
+	for _, par := range paresDoMapa(valorDoMapa(op.node, "responses")) {
+		node, origem := derreferenciar(idx, par.valor)
+		if node == nil {
+			continue
+		}
+		campo := op.ponteiro + "/responses/" + escaparPonteiro(par.chave.Value)
+		if origem != "" {
+			campo = origem
+		}
+		respostas = append(respostas, declaracao{campo, par.chave.Value, node})
+	}
+	return respostas
+}
+
+// Função para listar os headers de uma response
+func headersDaResposta(idx *index.SpecIndex, resposta declaracao) []declaracao {
+	var headers []declaracao
+	for _, par := range paresDoMapa(valorDoMapa(resposta.node, "headers")) {
+		node, origem := derreferenciar(idx, par.valor)
+		if node == nil {
+			continue
+		}
+		campo := resposta.campo + "/headers/" + escaparPonteiro(par.chave.Value)
+		if origem != "" {
+			campo = origem
+		}
+		headers = append(headers, declaracao{campo, par.chave.Value, node})
+	}
+	return headers
+}
+
+// Função para obter o schema de um parâmetro ou header; no Swagger 2.0 o tipo é declarado no próprio nó
+func schemaDaDeclaracao(idx *index.SpecIndex, node *yaml.Node) *yaml.Node {
+	if schema := valorDoMapa(node, "schema"); schema != nil {
+		schema, _ = derreferenciar(idx, schema)
+		return schema
+	}
+	if temChave(node, "type") {
+		return node
+	}
+	return nil
+}
+
+// Função para verificar se um status code corresponde a um padrão: o código exato, uma classe (4XX),
+// "default" ou "*" para todos
+func statusCorresponde(status, padrao string) bool {
+	status, padrao = strings.ToUpper(status), strings.ToUpper(padrao)
+	if padrao == "*" || padrao == status {
+		return true
+	}
+	return len(padrao) == 3 && strings.HasSuffix(padrao, "XX") && len(status) == 3 && status[0] == padrao[0]
+}
@@ -0,0 +1,84 @@
+package main
+
+import (
+	"embed"
+	"fmt"
+	"io/ioutil"
+	"path/filepath"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// pacotes de regras distribuídos junto com o validador, usados pelo nome em "extends"
+//
+//go:embed pacotes/*.yaml
+var pacotesEmbutidos embed.FS
+
+// Função para ler um pacote de regras: um pacote embutido pelo nome ou um arquivo relativo ao arquivo de regras
+func lerPacote(nome, ruleFile string) ([]byte, error) {
+	if data, err := pacotesEmbutidos.ReadFile("pacotes/" + nome + ".yaml"); err == nil {
+		return data, nil
+	}
+	if strings.Contains(nome, ":") {
+		return nil, fmt.Errorf("pacote de regras desconhecido %q", nome)
+	}
+	caminho := nome
+	if !filepath.IsAbs(caminho) {
+		caminho = filepath.Join(filepath.Dir(ruleFile), nome)
+	}
+	data, err := ioutil.ReadFile(caminho)
+	if err != nil {
+		return nil, fmt.Errorf("erro ao ler o pacote de regras %s: %v", nome, err)
+	}
+	return data, nil
+}
+
+// Função para incluir nas regras os pacotes listados em "extends"; as regras do próprio arquivo têm precedência
+// sobre as dos pacotes, e os pacotes podem estender outros pacotes
+func estenderRegras(rules map[string]interface{}, ruleFile string, carregados map[string]bool) error {
+	var pacotes []string
+	switch extends := rules["extends"].(type) {
+	case nil:
+		return nil
+	case string:
+		pacotes = []string{extends}
+	case []interface{}:
+		for _, item := range extends {
+			pacotes = append(pacotes, fmt.Sprint(item))
+		}
+	default:
+		return fmt.Errorf("extends deve ser o nome de um pacote ou uma lista de pacotes")
+	}
+
+	regras, _ := rules["rules"].(map[string]interface{})
+	if regras == nil {
+		regras = make(map[string]interface{})
+		rules["rules"] = regras
+	}
+	for _, nome := range pacotes {
+		if carregados[nome] {
+			continue
+		}
+		carregados[nome] = true
+
+		data, err := lerPacote(nome, ruleFile)
+		if err != nil {
+			return err
+		}
+		var pacote map[string]interface{}
+		if err := yaml.Unmarshal(data, &pacote); err != nil {
+			return fmt.Errorf("erro ao fazer unmarshal do pacote de regras %s: %v", nome, err)
+		}
+		if err := estenderRegras(pacote, ruleFile, carregados); err != nil {
+			return err
+		}
+		regrasPacote, _ := pacote["rules"].(map[string]interface{})
+		for nomeRegra, regra := range regrasPacote {
+			if _, ok := regras[nomeRegra]; !ok {
+				regras[nomeRegra] = regra
+			}
+		}
+	}
+	return nil
+}
@@ -0,0 +1,256 @@
+package main
+
+import (
+	"fmt"
+	"regexp/syntax"
+	"strings"
+	"time"
+
+	"github.com/dlclark/regexp2"
+	"gopkg.in/yaml.v3"
+)
+
+// tempo máximo de cada teste de um pattern, para que patterns com backtracking catastrófico não travem a validação
+const tempoLimitePattern = 100 * time.Millisecond
+
+// amostraMultilinha é testada depois das amostras de uma linha; o . do ECMA-262 não aceita quebras de linha,
+// então patterns como ^.*$ só seriam tratados como texto arbitrário sem ela
+const amostraMultilinha = "linha 1\nlinha 2"
+
+// ilimitado representa o comprimento máximo de um pattern que aceita valores de qualquer tamanho
+const ilimitado = -1
+
+// Função para compilar o pattern como expressão regular ECMA-262, que é o dialeto usado pelo JSON Schema
+func compilarECMA(pattern string) (*regexp2.Regexp, error) {
+	re, err := regexp2.Compile(pattern, regexp2.ECMAScript)
+	if err != nil {
+		return nil, err
+	}
+	re.MatchTimeout = tempoLimitePattern
+	return re, nil
+}
+
+// Função para verificar se o pattern aceita o valor; um teste que excede o tempo limite conta como aceito
+func aceita(re *regexp2.Regexp, valor string) bool {
+	ok, err := re.MatchString(valor)
+	return ok || err != nil
+}
+
+// Função para verificar se o pattern aceita o valor dentro do tempo limite; usada quando aceitar o valor é o problema
+func aceitaComCerteza(re *regexp2.Regexp, valor string) bool {
+	ok, err := re.MatchString(valor)
+	return ok && err == nil
+}
+
+// Função para verificar se a árvore do pattern está ancorada no início e no fim do texto, inclusive quando
+// cada alternativa tem as suas próprias âncoras (ex.: ^a$|^b$)
+func ancorado(re *syntax.Regexp) bool {
+	if re.Op == syntax.OpAlternate {
+		for _, sub := range re.Sub {
+			if !ancorado(sub) {
+				return false
+			}
+		}
+		return true
+	}
+	return inicioDoTexto(re) && fimDoTexto(re)
+}
+
+// Função para verificar se a árvore começa com ^; o parser do Go fatora prefixos comuns das alternativas,
+// então uma alternativa pode aparecer no meio da árvore
+func inicioDoTexto(re *syntax.Regexp) bool {
+	switch re.Op {
+	case syntax.OpCapture:
+		return inicioDoTexto(re.Sub[0])
+	case syntax.OpConcat:
+		return len(re.Sub) > 0 && inicioDoTexto(re.Sub[0])
+	case syntax.OpAlternate:
+		for _, sub := range re.Sub {
+			if !inicioDoTexto(sub) {
+				return false
+			}
+		}
+		return true
+	}
+	return re.Op == syntax.OpBeginText || re.Op == syntax.OpBeginLine
+}
+
+// Função para verificar se a árvore termina com $
+func fimDoTexto(re *syntax.Regexp) bool {
+	switch re.Op {
+	case syntax.OpCapture:
+		return fimDoTexto(re.Sub[0])
+	case syntax.OpConcat:
+		return len(re.Sub) > 0 && fimDoTexto(re.Sub[len(re.Sub)-1])
+	case syntax.OpAlternate:
+		for _, sub := range re.Sub {
+			if !fimDoTexto(sub) {
+				return false
+			}
+		}
+		return true
+	}
+	return re.Op == syntax.OpEndText || re.Op == syntax.OpEndLine
+}
+
+// Função para verificar se o nó repete um sub-pattern um número ilimitado de vezes
+func repeticaoIlimitada(re *syntax.Regexp) bool {
+	return re.Op == syntax.OpStar || re.Op == syntax.OpPlus || (re.Op == syntax.OpRepeat && re.Max == -1)
+}
+
+// Função para verificar se há na árvore uma repetição com número variável de vezes
+func repeticaoVariavel(re *syntax.Regexp) bool {
+	if re.Op == syntax.OpStar || re.Op == syntax.OpPlus || re.Op == syntax.OpQuest || (re.Op == syntax.OpRepeat && re.Max != re.Min) {
+		return true
+	}
+	for _, sub := range re.Sub {
+		if repeticaoVariavel(sub) {
+			return true
+		}
+	}
+	return false
+}
+
+// Função para encontrar quantificadores aninhados, como (a+)+ ou (\w*\s?)*, que causam backtracking
+// catastrófico quando o valor quase corresponde ao pattern
+func quantificadorAninhado(re *syntax.Regexp) (string, bool) {
+	if repeticaoIlimitada(re) && repeticaoVariavel(re.Sub[0]) {
+		return re.String(), true
+	}
+	for _, sub := range re.Sub {
+		if trecho, ok := quantificadorAninhado(sub); ok {
+			return trecho, true
+		}
+	}
+	return "", false
+}
+
+// Função para calcular o menor e o maior comprimento (em caracteres) dos valores aceitos pela árvore do pattern
+func comprimentos(re *syntax.Regexp) (int, int) {
+	somar := func(a, b int) int {
+		if a == ilimitado || b == ilimitado {
+			return ilimitado
+		}
+		return a + b
+	}
+	multiplicar := func(a, n int) int {
+		if a == ilimitado || n == ilimitado {
+			if a == 0 {
+				return 0
+			}
+			return ilimitado
+		}
+		return a * n
+	}
+	switch re.Op {
+	case syntax.OpLiteral:
+		return len(re.Rune), len(re.Rune)
+	case syntax.OpCharClass, syntax.OpAnyChar, syntax.OpAnyCharNotNL:
+		return 1, 1
+	case syntax.OpCapture:
+		return comprimentos(re.Sub[0])
+	case syntax.OpStar:
+		_, maximo := comprimentos(re.Sub[0])
+		return 0, multiplicar(maximo, ilimitado)
+	case syntax.OpPlus:
+		minimo, maximo := comprimentos(re.Sub[0])
+		return minimo, multiplicar(maximo, ilimitado)
+	case syntax.OpQuest:
+		_, maximo := comprimentos(re.Sub[0])
+		return 0, maximo
+	case syntax.OpRepeat:
+		minimo, maximo := comprimentos(re.Sub[0])
+		return minimo * re.Min, multiplicar(maximo, re.Max)
+	case syntax.OpConcat:
+		minimo, maximo := 0, 0
+		for _, sub := range re.Sub {
+			subMinimo, subMaximo := comprimentos(sub)
+			minimo, maximo = minimo+subMinimo, somar(maximo, subMaximo)
+		}
+		return minimo, maximo
+	case syntax.OpAlternate:
+		minimo, maximo := -1, 0
+		for _, sub := range re.Sub {
+			subMinimo, subMaximo := comprimentos(sub)
+			if minimo == -1 || subMinimo < minimo {
+				minimo = subMinimo
+			}
+			if maximo != ilimitado && (subMaximo == ilimitado || subMaximo > maximo) {
+				maximo = subMaximo
+			}
+		}
+		return minimo, maximo
+	}
+	// âncoras e demais asserções não consomem caracteres
+	return 0, 0
+}
+
+// Função para analisar um pattern: validade como ECMA-262, âncoras, valor vazio, texto arbitrário, backtracking
+// catastrófico e consistência com o minLength e o maxLength do schema
+func analisarPattern(pattern string, schema *yaml.Node, amostras []string) []string {
+	var problemas []string
+	adicionar := func(formato string, args ...interface{}) {
+		problemas = append(problemas, fmt.Sprintf(formato, args...))
+	}
+	re, err := compilarECMA(pattern)
+	if err != nil {
+		adicionar("não é uma expressão regular ECMA-262 válida: %v", err)
+		return problemas
+	}
+
+	// a árvore do Go é usada na análise estrutural; patterns com recursos exclusivos do ECMA-262 (ex.: lookahead)
+	// passam apenas pelos testes com valores
+	arvore, err := syntax.Parse(pattern, syntax.Perl)
+	if err == nil && !ancorado(arvore) {
+		adicionar("não está ancorado com ^ e $, então aceita valores que apenas contêm um trecho válido")
+	}
+	if aceita(re, "") {
+		adicionar("aceita o texto vazio")
+	}
+	arbitrario := len(amostras) > 0
+	for _, amostra := range amostras {
+		arbitrario = arbitrario && aceita(re, amostra)
+	}
+	switch {
+	case arbitrario && aceita(re, amostraMultilinha):
+		adicionar("aceita texto arbitrário, inclusive com quebras de linha, então não restringe o valor")
+	case arbitrario:
+		adicionar("aceita texto arbitrário, então não restringe o valor")
+	}
+	if err != nil {
+		return problemas
+	}
+
+	if trecho, ok := quantificadorAninhado(arvore); ok {
+		adicionar("tem quantificadores aninhados em %s, com risco de backtracking catastrófico", trecho)
+	}
+	minimo, maximo := comprimentos(arvore)
+	if maxLength, ok := inteiro(valorDoMapa(schema, "maxLength")); ok {
+		switch {
+		case maxLength < minimo:
+			adicionar("exige pelo menos %d caracteres, mas o maxLength é %d", minimo, maxLength)
+		case maximo != ilimitado && maxLength < maximo:
+			adicionar("aceita valores de até %d caracteres, que o maxLength %d rejeita", maximo, maxLength)
+		case maximo != ilimitado && maxLength > maximo:
+			adicionar("aceita no máximo %d caracteres, mas o maxLength permite %d", maximo, maxLength)
+		}
+	}
+	if minLength, ok := inteiro(valorDoMapa(schema, "minLength")); ok && maximo != ilimitado && minLength > maximo {
+		adicionar("aceita no máximo %d caracteres, mas o minLength é %d", maximo, minLength)
+	}
+	return problemas
+}
+
+// Função para validar a qualidade dos patterns dos schemas
+func validarQualidadePattern(schema *yaml.Node, validationErrors *[]error, p *percurso, r regra, amostras []string, campo string) []error {
+	if pattern, ok := texto(valorDoMapa(schema, "pattern")); ok {
+		if problemas := analisarPattern(pattern, schema, amostras); len(problemas) > 0 {
+			*validationErrors = append(*validationErrors, r.findingDetalhado(campo,
+				fmt.Sprintf("O pattern %q %s.", pattern, strings.Join(problemas, "; "))))
+		}
+	}
+	visitarFilhos(schema, p, campo, func(sub *yaml.Node, campo string) {
+		*validationErrors = validarQualidadePattern(sub, validationErrors, p, r, amostras, campo)
+	})
+	return *validationErrors
+}
@@ -0,0 +1,46 @@
+package main
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestAnalisarPattern(t *testing.T) {
+	amostras := []string{"a", "Texto livre, com espaços.", "!@#$%&*()", "1234567890", "ação çé"}
+	const (
+		naoAncorado = "não está ancorado com ^ e $, então aceita valores que apenas contêm um trecho válido"
+		vazio       = "aceita o texto vazio"
+		arbitrario  = "aceita texto arbitrário, então não restringe o valor"
+		multilinha  = "aceita texto arbitrário, inclusive com quebras de linha, então não restringe o valor"
+	)
+	casos := []struct {
+		nome      string
+		pattern   string
+		schema    string
+		problemas []string
+	}{
+		{"qualquer texto de uma linha", `^.*$`, "{}", []string{vazio, arbitrario}},
+		{"qualquer texto com limite", `^.{0,100}$`, "{maxLength: 100}", []string{vazio, arbitrario}},
+		{"qualquer texto com quebras de linha", `^[\w\W]*$`, "{}", []string{vazio, multilinha}},
+		{"\\w*\\W* sem âncoras", `\w*\W*`, "{}", []string{naoAncorado, vazio, multilinha}},
+		{"\\w*\\W* ancorado", `^\w*\W*$`, "{}", []string{vazio}},
+		{"quantificadores aninhados", `^(a+)+$`, "{}", []string{"tem quantificadores aninhados em (a+)+, com risco de backtracking catastrófico"}},
+		{"sem âncoras", `[0-9]{8}`, "{maxLength: 8}", []string{naoAncorado}},
+		{"âncoras por alternativa", `^a$|^b$`, "{maxLength: 1}", nil},
+		{"aceita vazio", `^\d*$`, "{}", []string{vazio}},
+		{"consistente", `^\d{8}$`, "{minLength: 8, maxLength: 8}", nil},
+		{"maxLength maior que o pattern", `^\d{8}$`, "{maxLength: 10}", []string{"aceita no máximo 8 caracteres, mas o maxLength permite 10"}},
+		{"maxLength menor que o mínimo", `^\d{8}$`, "{maxLength: 5}", []string{"exige pelo menos 8 caracteres, mas o maxLength é 5"}},
+		{"maxLength menor que o máximo", `^\d{1,10}$`, "{maxLength: 5}", []string{"aceita valores de até 10 caracteres, que o maxLength 5 rejeita"}},
+		{"minLength maior que o máximo", `^\d{8}$`, "{minLength: 10}", []string{"aceita no máximo 8 caracteres, mas o minLength é 10"}},
+		{"lookahead do ECMA-262", `^(?=\d)\d{8}$`, "{}", nil},
+		{"inválido", `^(\d{8}$`, "{}", []string{"não é uma expressão regular ECMA-262 válida: error parsing regexp: missing closing ) in `^(\\d{8}$`"}},
+	}
+	for _, c := range casos {
+		t.Run(c.nome, func(t *testing.T) {
+			if got := analisarPattern(c.pattern, mapaDeTeste(t, c.schema), amostras); !reflect.DeepEqual(got, c.problemas) {
+				t.Errorf("analisarPattern(%q) = %q, esperado %q", c.pattern, got, c.problemas)
+			}
+		})
+	}
+}
@@ -0,0 +1,182 @@
+package main
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+
+	"github.com/pb33f/libopenapi/index"
+	"gopkg.in/yaml.v3"
+)
+
+// perfilPaginacao é o contrato de paginação aplicado aos endpoints de listagem cujo path corresponde ao regex
+type perfilPaginacao struct {
+	paths          *regexp.Regexp // nil para valer em todos os paths
+	links          []string
+	linksProibidos []string
+	meta           []string
+	metaProibidos  []string
+}
+
+// Função para ler os perfis de paginação das opções da regra, na ordem em que foram declarados
+func perfisDePaginacao(opcoes map[string]interface{}) ([]perfilPaginacao, error) {
+	lista, _ := opcoes["profiles"].([]interface{})
+	var perfis []perfilPaginacao
+	for _, item := range lista {
+		dados, _ := item.(map[string]interface{})
+		perfil := perfilPaginacao{
+			links:          textosDaOpcao(dados["links"]),
+			linksProibidos: textosDaOpcao(dados["forbiddenLinks"]),
+			meta:           textosDaOpcao(dados["meta"]),
+			metaProibidos:  textosDaOpcao(dados["forbiddenMeta"]),
+		}
+		if padrao, ok := dados["paths"].(string); ok {
+			re, err := regexp.Compile(padrao)
+			if err != nil {
+				return nil, fmt.Errorf("regex de paths inválido %q: %v", padrao, err)
+			}
+			perfil.paths = re
+		}
+		perfis = append(perfis, perfil)
+	}
+	return perfis, nil
+}
+
+// Função para escolher o primeiro perfil de paginação que se aplica ao path
+func perfilDoPath(perfis []perfilPaginacao, path string) (perfilPaginacao, bool) {
+	for _, perfil := range perfis {
+		if perfil.paths == nil || perfil.paths.MatchString(path) {
+			return perfil, true
+		}
+	}
+	return perfilPaginacao{}, false
+}
+
+// Função para listar os schemas das responses 2xx da operação que retornam uma coleção, ou seja, que têm
+// na raiz do corpo a propriedade da coleção declarada como array
+func schemasDeColecao(idx *index.SpecIndex, op operacao, colecao string) []schemaLocalizado {
+	var schemas []schemaLocalizado
+	for _, resposta := range respostasDaOperacao(idx, op) {
+		if !statusCorresponde(resposta.nome, "2XX") {
+			continue
+		}
+		for _, schema := range schemasDaResposta(idx, resposta) {
+			if dados, ok := propriedadeDoSchema(idx, schema, colecao); ok && tipoDoSchema(idx, dados) == "array" {
+				schemas = append(schemas, schema)
+			}
+		}
+	}
+	return schemas
+}
+
+// Função para validar a presença e a ausência das propriedades de links ou meta de uma response de coleção
+func validarCamposDaPaginacao(idx *index.SpecIndex, schema schemaLocalizado, nome string, exigidos, proibidos []string, validationErrors *[]error, r regra, descricao string) {
+	if len(exigidos) == 0 && len(proibidos) == 0 {
+		return
+	}
+	objeto, ok := propriedadeDoSchema(idx, schema, nome)
+	if !ok {
+		if len(exigidos) > 0 {
+			*validationErrors = append(*validationErrors, r.findingDetalhado(schema.campo,
+				fmt.Sprintf("%s retorna uma coleção, mas não declara %s.", descricao, nome)))
+		}
+		return
+	}
+	var ausentes []string
+	for _, campo := range exigidos {
+		if _, ok := propriedadeDoSchema(idx, objeto, campo); !ok {
+			ausentes = append(ausentes, campo)
+		}
+	}
+	if len(ausentes) > 0 {
+		*validationErrors = append(*validationErrors, r.findingDetalhado(objeto.campo,
+			fmt.Sprintf("Em %s, %s deve declarar: %s.", descricao, nome, strings.Join(ausentes, ", "))))
+	}
+	for _, campo := range proibidos {
+		if proibido, ok := propriedadeDoSchema(idx, objeto, campo); ok {
+			*validationErrors = append(*validationErrors, r.findingDetalhado(proibido.campo,
+				fmt.Sprintf("Em %s, %s não deve declarar %s.", descricao, nome, campo)))
+		}
+	}
+}
+
+// Função para validar os parâmetros de query da paginação e os limites declarados nos seus schemas
+func validarParametrosDaPaginacao(idx *index.SpecIndex, op operacao, esperados map[string]interface{}, validationErrors *[]error, r regra, descricao string) {
+	parametros := parametrosDaOperacao(idx, op)
+	for _, nome := range chavesDaOpcao(esperados) {
+		var parametro *declaracao
+		for i := range parametros {
+			local, _ := texto(valorDoMapa(parametros[i].node, "in"))
+			if local == "query" && parametros[i].nome == nome {
+				parametro = &parametros[i]
+			}
+		}
+		if parametro == nil {
+			*validationErrors = append(*validationErrors, r.findingDetalhado(op.ponteiro,
+				fmt.Sprintf("%s não aceita o parâmetro de query %s.", descricao, nome)))
+			continue
+		}
+		schema := schemaDaDeclaracao(idx, parametro.node)
+		if !temTipo(schema, "integer") {
+			*validationErrors = append(*validationErrors, r.findingDetalhado(parametro.campo,
+				fmt.Sprintf("O parâmetro %s de %s deve ser do tipo integer.", nome, descricao)))
+		}
+		limites, _ := esperados[nome].(map[string]interface{})
+		if minimo, ok := limites["minimum"].(int); ok {
+			atual, definido := inteiro(valorDoMapa(schema, "minimum"))
+			switch {
+			case !definido:
+				*validationErrors = append(*validationErrors, r.findingDetalhado(parametro.campo,
+					fmt.Sprintf("O parâmetro %s de %s deve definir minimum (no mínimo %d).", nome, descricao, minimo)))
+			case atual < minimo:
+				*validationErrors = append(*validationErrors, r.findingDetalhado(parametro.campo,
+					fmt.Sprintf("O parâmetro %s de %s tem minimum %d, abaixo do limite de %d.", nome, descricao, atual, minimo)))
+			}
+		}
+		if maximo, ok := limites["maximum"].(int); ok {
+			atual, definido := inteiro(valorDoMapa(schema, "maximum"))
+			switch {
+			case !definido:
+				*validationErrors = append(*validationErrors, r.findingDetalhado(parametro.campo,
+					fmt.Sprintf("O parâmetro %s de %s deve definir maximum (no máximo %d).", nome, descricao, maximo)))
+			case atual > maximo:
+				*validationErrors = append(*validationErrors, r.findingDetalhado(parametro.campo,
+					fmt.Sprintf("O parâmetro %s de %s tem maximum %d, acima do limite de %d.", nome, descricao, atual, maximo)))
+			}
+		}
+	}
+}
+
+// Função para validar o contrato de paginação das operações GET que retornam coleções
+func validarPaginacao(doc *yaml.Node, idx *index.SpecIndex, validationErrors *[]error, r regra) []error {
+	opcoes := r.opcoes()
+	perfis, err := perfisDePaginacao(opcoes)
+	if err != nil {
+		*validationErrors = append(*validationErrors, r.findingDetalhado("", err.Error()))
+		return *validationErrors
+	}
+	colecao, _ := opcoes["collection"].(string)
+	if colecao == "" {
+		colecao = "data"
+	}
+	parametros, _ := opcoes["parameters"].(map[string]interface{})
+
+	for _, op := range listarOperacoes(doc) {
+		if op.metodo != "get" {
+			continue
+		}
+		schemas := schemasDeColecao(idx, op, colecao)
+		if len(schemas) == 0 {
+			continue
+		}
+		descricao := "GET " + op.path
+		if perfil, ok := perfilDoPath(perfis, op.path); ok {
+			for _, schema := range schemas {
+				validarCamposDaPaginacao(idx, schema, "links", perfil.links, perfil.linksProibidos, validationErrors, r, descricao)
+				validarCamposDaPaginacao(idx, schema, "meta", perfil.meta, perfil.metaProibidos, validationErrors, r, descricao)
+			}
+		}
+		validarParametrosDaPaginacao(idx, op, parametros, validationErrors, r, descricao)
+	}
+	return *validationErrors
+}
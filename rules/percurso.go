@@ -0,0 +1,128 @@
+package main
+
+import (
+	"strings"
+
+	"github.com/pb33f/libopenapi/index"
+	"gopkg.in/yaml.v3"
+)
+
+// percurso navega pelos schemas seguindo $refs locais e de arquivos através do índice do libopenapi,
+// visitando cada nó uma única vez para não entrar em loop em schemas recursivos
+type percurso struct {
+	indices   []*index.SpecIndex // pilha com o índice do arquivo que está sendo percorrido
+	raizes    map[*yaml.Node]bool
+	visitados map[*yaml.Node]bool
+	ancoras   map[*yaml.Node]string // localização das âncoras YAML, onde os findings de aliases são reportados
+}
+
+// Função para criar um percurso; os schemas raiz são validados na sua própria localização,
+// então um $ref para eles não é seguido
+func novoPercurso(idx *index.SpecIndex, schemas []localSchema) *percurso {
+	p := &percurso{
+		indices:   []*index.SpecIndex{idx},
+		raizes:    make(map[*yaml.Node]bool),
+		visitados: make(map[*yaml.Node]bool),
+		ancoras:   make(map[*yaml.Node]string),
+	}
+	if idx != nil {
+		p.ancoras = mapearAncoras(idx.GetRootNode())
+	}
+	for _, schema := range schemas {
+		p.raizes[schema.node] = true
+	}
+	return p
+}
+
+// Função para visitar um schema: resolve o $ref (se houver) e chama a visita com o conteúdo do schema
+// apenas na primeira vez que o nó é encontrado. Ao seguir um $ref para outro arquivo, o campo passa a ser
+// a localização do schema no próprio arquivo (ex.: schemas/Payment.yaml#/properties/nome)
+func (p *percurso) visitar(node *yaml.Node, campo string, visita func(schema *yaml.Node, campo string)) {
+	node = resolverAlias(node)
+	if node == nil {
+		return
+	}
+
+	idx := p.indices[len(p.indices)-1]
+	empilhados := 0
+	refsVistos := make(map[*yaml.Node]bool)
+	for {
+		ref, ok := texto(valorDoMapa(node, "$ref"))
+		if !ok || idx == nil || refsVistos[node] {
+			break
+		}
+		refsVistos[node] = true
+
+		encontrado, idxDestino := idx.SearchIndexForReference(ref)
+		if encontrado == nil || encontrado.Node == nil {
+			// referências não encontradas já são reportadas pelo índice
+			p.indices = p.indices[:len(p.indices)-empilhados]
+			return
+		}
+		node = encontrado.Node
+		if idxDestino != nil {
+			if arquivo := p.arquivo(idxDestino); arquivo != p.arquivo(idx) {
+				_, fragmento, _ := strings.Cut(encontrado.FullDefinition, "#")
+				campo = arquivo + "#" + fragmento
+			}
+			idx = idxDestino
+		}
+		p.indices = append(p.indices, idx)
+		empilhados++
+	}
+	defer func() {
+		p.indices = p.indices[:len(p.indices)-empilhados]
+	}()
+
+	if p.visitados[node] || (empilhados > 0 && p.raizes[node]) {
+		return
+	}
+	p.visitados[node] = true
+	visita(node, campo)
+}
+
+// Função para obter o arquivo de um índice, relativo ao diretório base; vazio para o documento principal,
+// cujos campos são JSON pointers locais
+func (p *percurso) arquivo(idx *index.SpecIndex) string {
+	raiz := p.indices[0]
+	if idx == raiz || raiz == nil || raiz.GetConfig() == nil {
+		return ""
+	}
+	config := raiz.GetConfig()
+	if idx.GetSpecAbsolutePath() == config.SpecFilePath {
+		return ""
+	}
+	return caminhoRelativo(idx.GetSpecAbsolutePath(), config.BasePath)
+}
+
+// Função para calcular o JSON pointer de um schema, usando a localização da âncora quando o schema
+// é compartilhado via alias
+func (p *percurso) campo(node *yaml.Node, campo string) string {
+	if ponteiro, ok := p.ancoras[resolverAlias(node)]; ok {
+		return ponteiro
+	}
+	return campo
+}
+
+// Função para calcular o JSON pointer do valor de um par, usando a localização da âncora quando o valor
+// vem de um alias ou de uma merge key
+func (p *percurso) campoDoPar(mapa *yaml.Node, par parYAML, campo string) string {
+	if ponteiro, ok := p.ancoras[par.valor]; ok {
+		return ponteiro
+	}
+	if par.origem != resolverAlias(mapa) {
+		if ponteiro, ok := p.ancoras[par.origem]; ok {
+			return ponteiro + "/" + escaparPonteiro(par.chave.Value)
+		}
+	}
+	return campo + "/" + escaparPonteiro(par.chave.Value)
+}
+
+// Função para detectar as referências circulares do documento e convertê-las em findings
+func validarReferenciasCirculares(idx *index.SpecIndex, politica politicaCircular) []error {
+	// o rolodex já procura os ciclos ao indexar; um novo resolver apagaria o resultado do índice
+	if idx.GetResolver() == nil {
+		index.NewResolver(idx).CheckForCircularReferences()
+	}
+	return findingsCirculares(idx.GetCircularReferences(), politica)
+}
@@ -0,0 +1,368 @@
+package main
+
+import (
+	"archive/tar"
+	"archive/zip"
+	"bytes"
+	"compress/gzip"
+	"errors"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"os"
+	"path"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"gopkg.in/yaml.v3"
+)
+
+// diretório de cache padrão do vendor-refs, relativo ao arquivo de mapeamento
+const cachePadrao = ".refs-cache"
+
+// mapaReferencias resolve $refs remotos a partir de arquivos locais, sem acesso à rede. Exemplo de configuração:
+//
+//	cache: .refs-cache
+//	mappings:
+//	  - prefix: https://openbanking-brasil.github.io/openapi/swagger-apis/common/
+//	    path: ./vendor/common
+//	  - prefix: https://example.com/schemas/
+//	    path: ./vendor/schemas.zip
+//
+// Cada prefixo aponta para um diretório ou um arquivo .zip/.tar.gz; URLs sem mapeamento são buscadas
+// no cache preenchido pelo comando vendor-refs (<cache>/<host>/<caminho>).
+type mapaReferencias struct {
+	mapeamentos []mapeamentoURL
+	cache       string
+	arquivos    map[string]map[string][]byte // conteúdo dos arquivos compactados já abertos
+}
+
+// mapeamentoURL associa um prefixo de URL a um diretório ou arquivo compactado local
+type mapeamentoURL struct {
+	Prefixo string `yaml:"prefix"`
+	Caminho string `yaml:"path"`
+}
+
+// Função para carregar o arquivo de mapeamento de referências; caminhos relativos são relativos ao arquivo
+func carregarMapaReferencias(arquivo string) (*mapaReferencias, error) {
+	data, err := readFile(arquivo)
+	if err != nil {
+		return nil, err
+	}
+
+	var config struct {
+		Cache       string          `yaml:"cache"`
+		Mapeamentos []mapeamentoURL `yaml:"mappings"`
+	}
+	if err := yaml.Unmarshal(data, &config); err != nil {
+		return nil, fmt.Errorf("erro ao fazer unmarshal do mapeamento de referências: %v", err)
+	}
+
+	diretorio := filepath.Dir(arquivo)
+	relativo := func(caminho string) string {
+		if filepath.IsAbs(caminho) {
+			return caminho
+		}
+		return filepath.Join(diretorio, caminho)
+	}
+
+	m := &mapaReferencias{cache: relativo(cachePadrao), arquivos: make(map[string]map[string][]byte)}
+	if config.Cache != "" {
+		m.cache = relativo(config.Cache)
+	}
+	for i, mapeamento := range config.Mapeamentos {
+		if !ehURL(mapeamento.Prefixo) || mapeamento.Caminho == "" {
+			return nil, fmt.Errorf("mapeamento %d inválido: prefix deve ser uma URL http(s) e path não pode ser vazio", i+1)
+		}
+		mapeamento.Caminho = relativo(mapeamento.Caminho)
+		m.mapeamentos = append(m.mapeamentos, mapeamento)
+	}
+	// o prefixo mais específico tem precedência
+	sort.SliceStable(m.mapeamentos, func(i, j int) bool {
+		return len(m.mapeamentos[i].Prefixo) > len(m.mapeamentos[j].Prefixo)
+	})
+	return m, nil
+}
+
+// Função para ler o documento de uma URL a partir dos mapeamentos ou do cache, sem acesso à rede
+func (m *mapaReferencias) ler(endereco string) ([]byte, error) {
+	endereco = semFragmento(endereco)
+	for _, mapeamento := range m.mapeamentos {
+		if strings.HasPrefix(endereco, mapeamento.Prefixo) {
+			return m.lerMapeado(mapeamento, strings.TrimPrefix(endereco, mapeamento.Prefixo))
+		}
+	}
+
+	caminho, err := m.caminhoNoCache(endereco)
+	if err != nil {
+		return nil, err
+	}
+	data, err := ioutil.ReadFile(caminho)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, fmt.Errorf("%s não está mapeada nem no cache %s; execute o comando vendor-refs", endereco, m.cache)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("erro ao ler %s do cache: %v", endereco, err)
+	}
+	return data, nil
+}
+
+// Função para ler um arquivo de um mapeamento, que pode ser um diretório ou um arquivo compactado
+func (m *mapaReferencias) lerMapeado(mapeamento mapeamentoURL, relativo string) ([]byte, error) {
+	relativo = strings.TrimPrefix(path.Clean("/"+relativo), "/")
+	nome := strings.ToLower(mapeamento.Caminho)
+	if !strings.HasSuffix(nome, ".zip") && !strings.HasSuffix(nome, ".tar.gz") && !strings.HasSuffix(nome, ".tgz") {
+		data, err := ioutil.ReadFile(filepath.Join(mapeamento.Caminho, filepath.FromSlash(relativo)))
+		if err != nil {
+			return nil, fmt.Errorf("erro ao ler %s%s em %s: %v", mapeamento.Prefixo, relativo, mapeamento.Caminho, err)
+		}
+		return data, nil
+	}
+
+	arquivos, ok := m.arquivos[mapeamento.Caminho]
+	if !ok {
+		var err error
+		if arquivos, err = lerCompactado(mapeamento.Caminho); err != nil {
+			return nil, err
+		}
+		m.arquivos[mapeamento.Caminho] = arquivos
+	}
+	if data, ok := arquivos[relativo]; ok {
+		return data, nil
+	}
+	// arquivos gerados pelo GitHub e similares colocam tudo dentro de um diretório raiz (repo-main/)
+	for nome, data := range arquivos {
+		if i := strings.Index(nome, "/"); i >= 0 && nome[i+1:] == relativo {
+			return data, nil
+		}
+	}
+	return nil, fmt.Errorf("%s%s não encontrado em %s", mapeamento.Prefixo, relativo, mapeamento.Caminho)
+}
+
+// Função para ler todos os arquivos de um .zip ou .tar.gz
+func lerCompactado(caminho string) (map[string][]byte, error) {
+	arquivos := make(map[string][]byte)
+	if strings.HasSuffix(strings.ToLower(caminho), ".zip") {
+		leitor, err := zip.OpenReader(caminho)
+		if err != nil {
+			return nil, fmt.Errorf("erro ao abrir %s: %v", caminho, err)
+		}
+		defer leitor.Close()
+		for _, arquivo := range leitor.File {
+			if arquivo.FileInfo().IsDir() {
+				continue
+			}
+			conteudo, err := arquivo.Open()
+			if err != nil {
+				return nil, fmt.Errorf("erro ao ler %s em %s: %v", arquivo.Name, caminho, err)
+			}
+			data, err := ioutil.ReadAll(conteudo)
+			conteudo.Close()
+			if err != nil {
+				return nil, fmt.Errorf("erro ao ler %s em %s: %v", arquivo.Name, caminho, err)
+			}
+			arquivos[strings.TrimPrefix(arquivo.Name, "./")] = data
+		}
+		return arquivos, nil
+	}
+
+	arquivo, err := os.Open(caminho)
+	if err != nil {
+		return nil, fmt.Errorf("erro ao abrir %s: %v", caminho, err)
+	}
+	defer arquivo.Close()
+	descompactado, err := gzip.NewReader(arquivo)
+	if err != nil {
+		return nil, fmt.Errorf("erro ao descompactar %s: %v", caminho, err)
+	}
+	leitor := tar.NewReader(descompactado)
+	for {
+		cabecalho, err := leitor.Next()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, fmt.Errorf("erro ao ler %s: %v", caminho, err)
+		}
+		if cabecalho.Typeflag != tar.TypeReg {
+			continue
+		}
+		data, err := ioutil.ReadAll(leitor)
+		if err != nil {
+			return nil, fmt.Errorf("erro ao ler %s em %s: %v", cabecalho.Name, caminho, err)
+		}
+		arquivos[strings.TrimPrefix(cabecalho.Name, "./")] = data
+	}
+	return arquivos, nil
+}
+
+// Função para calcular o caminho de uma URL no cache: <cache>/<host>/<caminho>
+func (m *mapaReferencias) caminhoNoCache(endereco string) (string, error) {
+	u, err := url.Parse(semFragmento(endereco))
+	if err != nil || u.Host == "" {
+		return "", fmt.Errorf("URL inválida %q", endereco)
+	}
+	caminho := strings.TrimPrefix(path.Clean("/"+u.Path), "/")
+	if caminho == "" {
+		return "", fmt.Errorf("a URL %s não aponta para um documento", endereco)
+	}
+	// ":" da porta não é válido em nomes de diretório no Windows
+	return filepath.Join(m.cache, strings.ReplaceAll(u.Host, ":", "_"), filepath.FromSlash(caminho)), nil
+}
+
+// Função usada pelo rolodex para buscar documentos remotos; responde com o conteúdo local, sem acesso à rede
+func (m *mapaReferencias) handler(endereco string) (*http.Response, error) {
+	data, err := m.ler(endereco)
+	if err != nil {
+		return nil, err
+	}
+	return &http.Response{
+		StatusCode: http.StatusOK,
+		Header:     make(http.Header),
+		Body:       ioutil.NopCloser(bytes.NewReader(data)),
+	}, nil
+}
+
+// Função para baixar (ou copiar, quando mapeado) os documentos remotos referenciados pelas especificações,
+// salvando no cache para que as próximas execuções não precisem de rede
+func vendorizarReferencias(specs []string, baseDir string, m *mapaReferencias) error {
+	type pendente struct {
+		localizacao string // arquivo ou URL do documento
+		base        string // localização usada para resolver os $refs relativos do documento
+	}
+
+	var fila []pendente
+	for _, spec := range specs {
+		base := spec
+		if baseDir != "" {
+			base = filepath.Join(baseDir, filepath.Base(spec))
+		}
+		fila = append(fila, pendente{spec, base})
+	}
+
+	cliente := &http.Client{Timeout: 30 * time.Second}
+	visitados := make(map[string]bool)
+	salvos, falhas := 0, 0
+	for len(fila) > 0 {
+		atual := fila[0]
+		fila = fila[1:]
+		if visitados[atual.localizacao] {
+			continue
+		}
+		visitados[atual.localizacao] = true
+
+		var data []byte
+		var err error
+		if ehURL(atual.localizacao) {
+			data, err = m.vendorizar(atual.localizacao, cliente)
+			if err == nil {
+				salvos++
+			}
+		} else {
+			data, err = readFile(atual.localizacao)
+		}
+		if err != nil {
+			fmt.Println("❌", err)
+			falhas++
+			continue
+		}
+
+		rootNode, _, err := lerDocumento(data)
+		if err != nil {
+			fmt.Println("❌", atual.localizacao+":", err)
+			falhas++
+			continue
+		}
+		for _, ref := range coletarRefs(rootNode, nil) {
+			if alvo := semFragmento(ref); alvo != "" {
+				proximo := resolverLocalizacao(atual.base, alvo)
+				fila = append(fila, pendente{proximo, proximo})
+			}
+		}
+	}
+
+	if falhas > 0 {
+		return fmt.Errorf("%d documento(s) não puderam ser salvos no cache", falhas)
+	}
+	fmt.Printf("✅ %d documento(s) remoto(s) salvos em %s\n", salvos, m.cache)
+	return nil
+}
+
+// Função para salvar um documento remoto no cache, copiando do mapeamento local ou baixando da URL
+func (m *mapaReferencias) vendorizar(endereco string, cliente *http.Client) ([]byte, error) {
+	destino, err := m.caminhoNoCache(endereco)
+	if err != nil {
+		return nil, err
+	}
+
+	origem := "copiado"
+	data, err := m.ler(endereco)
+	if err != nil {
+		origem = "baixado"
+		if data, err = baixar(cliente, semFragmento(endereco)); err != nil {
+			return nil, err
+		}
+	}
+
+	if err := os.MkdirAll(filepath.Dir(destino), 0755); err != nil {
+		return nil, fmt.Errorf("erro ao criar o diretório do cache: %v", err)
+	}
+	if err := ioutil.WriteFile(destino, data, 0644); err != nil {
+		return nil, fmt.Errorf("erro ao salvar %s no cache: %v", endereco, err)
+	}
+	fmt.Printf("📥 %s (%s) -> %s\n", endereco, origem, destino)
+	return data, nil
+}
+
+// Função para baixar um documento por HTTP
+func baixar(cliente *http.Client, endereco string) ([]byte, error) {
+	resposta, err := cliente.Get(endereco)
+	if err != nil {
+		return nil, fmt.Errorf("erro ao baixar %s: %v", endereco, err)
+	}
+	defer resposta.Body.Close()
+	if resposta.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("erro ao baixar %s: status %d", endereco, resposta.StatusCode)
+	}
+	data, err := ioutil.ReadAll(resposta.Body)
+	if err != nil {
+		return nil, fmt.Errorf("erro ao baixar %s: %v", endereco, err)
+	}
+	return data, nil
+}
+
+// Função para verificar se uma localização é uma URL http(s)
+func ehURL(localizacao string) bool {
+	return strings.HasPrefix(localizacao, "http://") || strings.HasPrefix(localizacao, "https://")
+}
+
+// Função para remover o fragmento (#/components/...) de um $ref
+func semFragmento(ref string) string {
+	if i := strings.Index(ref, "#"); i >= 0 {
+		return ref[:i]
+	}
+	return ref
+}
+
+// Função para resolver a localização de um $ref relativo ao documento onde ele está escrito
+func resolverLocalizacao(base, alvo string) string {
+	if ehURL(alvo) || filepath.IsAbs(alvo) {
+		return alvo
+	}
+	if ehURL(base) {
+		baseURL, err := url.Parse(base)
+		if err != nil {
+			return alvo
+		}
+		relativa, err := url.Parse(alvo)
+		if err != nil {
+			return alvo
+		}
+		return baseURL.ResolveReference(relativa).String()
+	}
+	return filepath.Join(filepath.Dir(base), filepath.FromSlash(alvo))
+}
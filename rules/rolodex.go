@@ -0,0 +1,341 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"io/ioutil"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/pb33f/libopenapi/index"
+	"gopkg.in/yaml.v3"
+)
+
+// opcoesReferencias configura como o rolodex encontra os documentos referenciados
+type opcoesReferencias struct {
+	baseDir    string           // diretório dos $refs relativos do documento; vazio usa o diretório do arquivo
+	mapa       *mapaReferencias // mapeamento de URLs para arquivos locais; nil mantém os $refs remotos desabilitados
+	circulares politicaCircular // tratamento das referências circulares; vazio equivale a warn
+}
+
+// politicaCircular define como as referências circulares que podem ser representadas são reportadas;
+// ciclos infinitos (todos os schemas do ciclo obrigatórios) são sempre erros
+type politicaCircular string
+
+const (
+	circularPermitir politicaCircular = "allow"
+	circularAvisar   politicaCircular = "warn"
+	circularErro     politicaCircular = "error"
+)
+
+// Função para converter o valor da flag --circular-refs
+func parsePoliticaCircular(valor string) (politicaCircular, error) {
+	switch politica := politicaCircular(strings.ToLower(valor)); politica {
+	case circularPermitir, circularAvisar, circularErro:
+		return politica, nil
+	}
+	return "", fmt.Errorf("tratamento de referências circulares inválido %q, use allow, warn ou error", valor)
+}
+
+// Função para criar o rolodex do documento com acesso aos arquivos locais e, se houver mapeamento,
+// aos documentos remotos mapeados para arquivos locais
+func novoRolodex(rootNode *yaml.Node, specFile string, opcoes opcoesReferencias) (*index.Rolodex, error) {
+	baseDir := opcoes.baseDir
+	if baseDir == "" {
+		baseDir = filepath.Dir(specFile)
+	}
+	baseDir, err := filepath.Abs(baseDir)
+	if err != nil {
+		return nil, fmt.Errorf("erro ao obter o diretório base %s: %v", baseDir, err)
+	}
+	if info, err := os.Stat(baseDir); err != nil || !info.IsDir() {
+		return nil, fmt.Errorf("o diretório base %s não existe ou não é um diretório", baseDir)
+	}
+	specPath, err := filepath.Abs(specFile)
+	if err != nil {
+		return nil, fmt.Errorf("erro ao obter o caminho de %s: %v", specFile, err)
+	}
+
+	// os erros de leitura viram findings; o log do libopenapi só duplicaria as mensagens
+	logger := slog.New(slog.NewTextHandler(io.Discard, nil))
+
+	indexConfig := index.CreateClosedAPIIndexConfig()
+	indexConfig.AllowFileLookup = true
+	indexConfig.BasePath = baseDir
+	indexConfig.SpecFilePath = specPath
+	indexConfig.Logger = logger
+
+	localFS, err := index.NewLocalFSWithConfig(&index.LocalFSConfig{
+		BaseDirectory: baseDir,
+		IndexConfig:   indexConfig,
+		Logger:        logger,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("erro ao acessar o diretório base %s: %v", baseDir, err)
+	}
+
+	rolodex := index.NewRolodex(indexConfig)
+	rolodex.AddLocalFS(baseDir, localFS)
+
+	// os $refs remotos são atendidos pelo mapeamento, sem acesso à rede
+	if opcoes.mapa != nil {
+		indexConfig.AllowRemoteLookup = true
+		indexConfig.RemoteURLHandler = opcoes.mapa.handler
+		remoteFS, err := index.NewRemoteFSWithConfig(indexConfig)
+		if err != nil {
+			return nil, fmt.Errorf("erro ao configurar as referências remotas: %v", err)
+		}
+		remoteFS.SetRemoteHandlerFunc(opcoes.mapa.handler)
+		rolodex.AddRemoteFS("", remoteFS)
+	}
+	rolodex.SetRootNode(rootNode)
+
+	// erros de referência são coletados dos índices por errosDeReferencia
+	_ = rolodex.IndexTheRolodex()
+	if rolodex.GetRootIndex() == nil {
+		return nil, fmt.Errorf("erro ao indexar o documento %s", specFile)
+	}
+	return rolodex, nil
+}
+
+// Função para coletar os erros de referência de todos os documentos do rolodex; $refs para arquivos ou
+// documentos remotos ausentes, ilegíveis ou inválidos viram findings na localização do $ref
+func errosDeReferencia(rolodex *index.Rolodex) []error {
+	raiz := rolodex.GetRootIndex()
+	config := rolodex.GetConfig()
+	baseDir := config.BasePath
+
+	// o arquivo da especificação também é indexado pelo sistema de arquivos local; essa cópia é ignorada
+	indices := []*index.SpecIndex{raiz}
+	for _, idx := range rolodex.GetIndexes() {
+		if idx != nil && idx != raiz && idx.GetSpecAbsolutePath() != config.SpecFilePath {
+			indices = append(indices, idx)
+		}
+	}
+
+	var validationErrors []error
+	// o mesmo erro pode ser registrado por mais de um índice; a chave é o nó do $ref e a mensagem
+	type chaveErro struct {
+		node     *yaml.Node
+		mensagem string
+	}
+	vistos := make(map[chaveErro]bool)
+	documentos := make(map[string]string)
+	for _, idx := range indices {
+		// os $refs do documento principal são relativos ao diretório base; os dos demais, ao próprio documento
+		origem, base := config.SpecFilePath, filepath.Join(baseDir, filepath.Base(config.SpecFilePath))
+		if idx != raiz && idx.GetSpecAbsolutePath() != "" {
+			origem = idx.GetSpecAbsolutePath()
+			base = origem
+		}
+
+		for _, ref := range idx.GetRawReferencesSequenced() {
+			refNode := valorDoMapa(ref.Node, "$ref")
+			alvo := alvoVerificavel(refNode, config)
+			chave := chaveErro{node: refNode, mensagem: alvo}
+			if alvo == "" || vistos[chave] {
+				continue
+			}
+			vistos[chave] = true
+
+			localizacao := resolverLocalizacao(base, alvo)
+			if problema := verificarDocumento(localizacao, config, documentos); problema != "" {
+				regra, tipo := "unresolved-file-ref", "arquivo"
+				if ehURL(localizacao) {
+					regra, tipo = "unresolved-remote-ref", "documento remoto"
+				}
+				validationErrors = append(validationErrors, &finding{
+					regra:      regra,
+					severidade: "error",
+					campo:      caminhoRelativo(origem, baseDir),
+					descricao:  fmt.Sprintf("O %s %s referenciado por %q %s", tipo, alvo, refNode.Value, problema),
+					linha:      refNode.Line,
+					coluna:     refNode.Column,
+				})
+			}
+		}
+
+		for _, err := range idx.GetReferenceIndexErrors() {
+			chave := chaveErro{mensagem: err.Error()}
+			var indexingError *index.IndexingError
+			if errors.As(err, &indexingError) {
+				chave.node = indexingError.Node
+				// erros de $refs para outros documentos já foram reportados acima, com a causa
+				if alvoVerificavel(valorDoMapa(indexingError.Node, "$ref"), config) != "" {
+					continue
+				}
+			}
+			if vistos[chave] {
+				continue
+			}
+			vistos[chave] = true
+			if indexingError != nil {
+				err = findingDeRef(indexingError.Node, indexingError.Err, caminhoRelativo(origem, baseDir))
+			}
+			validationErrors = append(validationErrors, err)
+		}
+	}
+	return validationErrors
+}
+
+// Função para criar o finding de um $ref que não pode ser resolvido, na linha do valor do $ref
+func findingDeRef(node *yaml.Node, causa error, campo string) error {
+	refNode := valorDoMapa(node, "$ref")
+	if refNode == nil {
+		refNode = resolverAlias(node)
+	}
+	f := &finding{regra: "unresolved-ref", severidade: "error", campo: campo}
+	if refNode == nil {
+		f.descricao = fmt.Sprintf("Referência não resolvida: %v", causa)
+		return f
+	}
+	f.descricao = fmt.Sprintf("O $ref %q não pode ser resolvido: %v", refNode.Value, causa)
+	f.linha, f.coluna = refNode.Line, refNode.Column
+	return f
+}
+
+// Função para coletar os problemas encontrados ao resolver as referências do rolodex: $refs que não puderam
+// ser substituídos e referências circulares, que ficam como $ref no documento resolvido
+func errosDeResolucao(rolodex *index.Rolodex, politica politicaCircular) []error {
+	campo := caminhoRelativo(rolodex.GetConfig().SpecFilePath, rolodex.GetConfig().BasePath)
+
+	var validationErrors []error
+	circulares := rolodex.GetSafeCircularReferences()
+	for _, err := range rolodex.GetCaughtErrors() {
+		var resolvingError *index.ResolvingError
+		if !errors.As(err, &resolvingError) {
+			validationErrors = append(validationErrors, err)
+			continue
+		}
+		if resolvingError.CircularReference != nil {
+			circulares = append(circulares, resolvingError.CircularReference)
+			continue
+		}
+		validationErrors = append(validationErrors, findingDeRef(resolvingError.Node, resolvingError.ErrorRef, campo))
+	}
+	return append(validationErrors, findingsCirculares(circulares, politica)...)
+}
+
+// Função para criar os findings das referências circulares conforme a política; o campo é o JSON pointer
+// do schema onde o ciclo começa, localizado depois por localizarFindings
+func findingsCirculares(circulares []*index.CircularReferenceResult, politica politicaCircular) []error {
+	var validationErrors []error
+	vistos := make(map[string]bool)
+	for _, circular := range circulares {
+		severidade := string(politica)
+		if politica == "" {
+			severidade = string(circularAvisar)
+		}
+		if circular.IsInfiniteLoop {
+			severidade = string(circularErro)
+		} else if politica == circularPermitir {
+			continue
+		}
+
+		campo, ref := "", ""
+		if circular.Start != nil {
+			campo = circular.Start.Definition
+		}
+		if circular.LoopPoint != nil {
+			ref = circular.LoopPoint.Definition
+		}
+		caminho := circular.GenerateJourneyPath()
+		if vistos[campo+caminho] {
+			continue
+		}
+		vistos[campo+caminho] = true
+
+		descricao := fmt.Sprintf("Referência circular encontrada: %s", caminho)
+		if ref != "" {
+			descricao += fmt.Sprintf(" (o $ref %q fecha o ciclo)", ref)
+		}
+		validationErrors = append(validationErrors, &finding{
+			regra:      "circular-reference",
+			severidade: severidade,
+			campo:      campo,
+			descricao:  descricao,
+		})
+	}
+	// os resultados do libopenapi vêm de mapas; a ordem é fixada para a saída ser estável
+	sort.SliceStable(validationErrors, func(i, j int) bool {
+		return validationErrors[i].Error() < validationErrors[j].Error()
+	})
+	return validationErrors
+}
+
+// Função para obter o documento de um $ref (./schemas/Payment.yaml#/Payment) que pode ser verificado:
+// arquivos locais e, quando há mapeamento, URLs. Retorna vazio para referências internas
+func alvoVerificavel(refNode *yaml.Node, config *index.SpecIndexConfig) string {
+	ref, ok := texto(refNode)
+	if !ok {
+		return ""
+	}
+	alvo := semFragmento(ref)
+	if strings.Contains(alvo, "://") && (!ehURL(alvo) || config.RemoteURLHandler == nil) {
+		return ""
+	}
+	return alvo
+}
+
+// Função para verificar se um documento referenciado pode ser lido e interpretado; retorna a descrição
+// do problema ou vazio. O resultado fica em cache, pois o mesmo documento costuma ser referenciado várias vezes
+func verificarDocumento(localizacao string, config *index.SpecIndexConfig, cache map[string]string) string {
+	if problema, ok := cache[localizacao]; ok {
+		return problema
+	}
+	cache[localizacao] = descreverProblema(localizacao, config)
+	return cache[localizacao]
+}
+
+func descreverProblema(localizacao string, config *index.SpecIndexConfig) string {
+	var data []byte
+	if ehURL(localizacao) {
+		if config.RemoteURLHandler == nil {
+			return ""
+		}
+		resposta, err := config.RemoteURLHandler(localizacao)
+		if err != nil {
+			return fmt.Sprintf("não está disponível offline: %v", err)
+		}
+		defer resposta.Body.Close()
+		if data, err = ioutil.ReadAll(resposta.Body); err != nil {
+			return fmt.Sprintf("não pode ser lido: %v", err)
+		}
+	} else {
+		var err error
+		data, err = ioutil.ReadFile(localizacao)
+		switch {
+		case errors.Is(err, fs.ErrNotExist):
+			return "não existe"
+		case errors.Is(err, fs.ErrPermission):
+			return "não pode ser lido: permissão negada"
+		case err != nil:
+			return fmt.Sprintf("não pode ser lido: %v", err)
+		}
+	}
+
+	utf8Data, err := convertToUTF8(data)
+	if err != nil {
+		return fmt.Sprintf("não pode ser lido: %v", err)
+	}
+	if _, _, err := lerDocumento(utf8Data); err != nil {
+		return fmt.Sprintf("não é um documento válido: %v", err)
+	}
+	return ""
+}
+
+// Função para exibir o caminho de um arquivo relativo ao diretório base, quando ele está dentro do diretório
+func caminhoRelativo(caminho, baseDir string) string {
+	if ehURL(caminho) {
+		return caminho
+	}
+	if relativo, err := filepath.Rel(baseDir, caminho); err == nil && !strings.HasPrefix(relativo, "..") {
+		return filepath.ToSlash(relativo)
+	}
+	return caminho
+}
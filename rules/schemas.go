@@ -0,0 +1,278 @@
+package main
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// contexto indica se um schema é usado em requisições, respostas ou em ambos
+type contexto int
+
+const (
+	contextoRequest contexto = 1 << iota
+	contextoResponse
+	contextoAmbos = contextoRequest | contextoResponse
+)
+
+// Função para converter o valor do campo "context" de uma regra
+func parseContexto(valor string) (contexto, error) {
+	switch valor {
+	case "request":
+		return contextoRequest, nil
+	case "response":
+		return contextoResponse, nil
+	case "both":
+		return contextoAmbos, nil
+	}
+	return 0, fmt.Errorf("contexto inválido %q, use request, response ou both", valor)
+}
+
+// localSchema representa um schema encontrado no documento junto com o JSON pointer da sua localização
+// e os contextos (request/response) a partir dos quais ele é alcançável
+type localSchema struct {
+	ponteiro string
+	node     *yaml.Node
+	contexto contexto
+}
+
+var metodosHTTP = []string{"get", "put", "post", "delete", "options", "head", "patch", "trace"}
+
+// Função para escapar um segmento de JSON pointer (RFC 6901)
+func escaparPonteiro(segmento string) string {
+	return strings.ReplaceAll(strings.ReplaceAll(segmento, "~", "~0"), "/", "~1")
+}
+
+// Função para coletar todos os schemas do documento: componentes (ou definitions do Swagger 2.0), parâmetros,
+// request bodies, responses, headers e webhooks
+func coletarSchemas(rootNode *yaml.Node) []localSchema {
+	var schemas []localSchema
+	doc := documento(rootNode)
+
+	components := valorDoMapa(doc, "components")
+	if componentSchemas := valorDoMapa(components, "schemas"); componentSchemas != nil {
+		for _, par := range paresDoMapa(componentSchemas) {
+			schemas = append(schemas, localSchema{ponteiro: "#/components/schemas/" + escaparPonteiro(par.chave.Value), node: par.valor})
+		}
+	}
+	if parametros := valorDoMapa(components, "parameters"); parametros != nil {
+		for _, par := range paresDoMapa(parametros) {
+			ponteiro := "#/components/parameters/" + escaparPonteiro(par.chave.Value)
+			schemas = coletarSchemasParametro(par.valor, ponteiro, contextoRequest, schemas)
+		}
+	}
+	if requestBodies := valorDoMapa(components, "requestBodies"); requestBodies != nil {
+		for _, par := range paresDoMapa(requestBodies) {
+			ponteiro := "#/components/requestBodies/" + escaparPonteiro(par.chave.Value)
+			schemas = coletarSchemasConteudo(par.valor, ponteiro, contextoRequest, schemas)
+		}
+	}
+	if responses := valorDoMapa(components, "responses"); responses != nil {
+		for _, par := range paresDoMapa(responses) {
+			ponteiro := "#/components/responses/" + escaparPonteiro(par.chave.Value)
+			schemas = coletarSchemasResponse(par.valor, ponteiro, schemas)
+		}
+	}
+	if headers := valorDoMapa(components, "headers"); headers != nil {
+		for _, par := range paresDoMapa(headers) {
+			ponteiro := "#/components/headers/" + escaparPonteiro(par.chave.Value)
+			schemas = coletarSchemasParametro(par.valor, ponteiro, contextoResponse, schemas)
+		}
+	}
+
+	// Swagger 2.0: schemas em "definitions" e parâmetros/responses reutilizáveis na raiz do documento
+	if definitions := valorDoMapa(doc, "definitions"); definitions != nil {
+		for _, par := range paresDoMapa(definitions) {
+			schemas = append(schemas, localSchema{ponteiro: "#/definitions/" + escaparPonteiro(par.chave.Value), node: par.valor})
+		}
+	}
+	if parametros := valorDoMapa(doc, "parameters"); parametros != nil {
+		for _, par := range paresDoMapa(parametros) {
+			schemas = coletarSchemasParametro(par.valor, "#/parameters/"+escaparPonteiro(par.chave.Value), contextoRequest, schemas)
+		}
+	}
+	if responses := valorDoMapa(doc, "responses"); responses != nil {
+		for _, par := range paresDoMapa(responses) {
+			schemas = coletarSchemasResponse(par.valor, "#/responses/"+escaparPonteiro(par.chave.Value), schemas)
+		}
+	}
+
+	schemas = coletarSchemasPathItems(valorDoMapa(doc, "paths"), "#/paths/", schemas)
+	// OpenAPI 3.1: webhooks têm a mesma estrutura dos path items
+	schemas = coletarSchemasPathItems(valorDoMapa(doc, "webhooks"), "#/webhooks/", schemas)
+
+	propagarContextos(schemas)
+	return schemas
+}
+
+// Função para coletar os schemas dos parâmetros, request bodies e responses de cada operação dos path items
+func coletarSchemasPathItems(pathItems *yaml.Node, prefixo string, schemas []localSchema) []localSchema {
+	for _, par := range paresDoMapa(pathItems) {
+		pathPonteiro := prefixo + escaparPonteiro(par.chave.Value)
+		pathItem := par.valor
+		schemas = coletarSchemasParametros(valorDoMapa(pathItem, "parameters"), pathPonteiro+"/parameters", schemas)
+
+		for _, metodo := range metodosHTTP {
+			operacao := valorDoMapa(pathItem, metodo)
+			if operacao == nil {
+				continue
+			}
+			operacaoPonteiro := pathPonteiro + "/" + metodo
+			schemas = coletarSchemasParametros(valorDoMapa(operacao, "parameters"), operacaoPonteiro+"/parameters", schemas)
+			schemas = coletarSchemasConteudo(valorDoMapa(operacao, "requestBody"), operacaoPonteiro+"/requestBody", contextoRequest, schemas)
+
+			for _, resposta := range paresDoMapa(valorDoMapa(operacao, "responses")) {
+				ponteiro := operacaoPonteiro + "/responses/" + escaparPonteiro(resposta.chave.Value)
+				schemas = coletarSchemasResponse(resposta.valor, ponteiro, schemas)
+			}
+		}
+	}
+	return schemas
+}
+
+// Função para coletar os schemas de uma lista de parâmetros
+func coletarSchemasParametros(parametros *yaml.Node, ponteiro string, schemas []localSchema) []localSchema {
+	for i, parametro := range itensDaLista(parametros) {
+		schemas = coletarSchemasParametro(parametro, ponteiro+"/"+strconv.Itoa(i), contextoRequest, schemas)
+	}
+	return schemas
+}
+
+// Função para coletar o schema de um parâmetro ou header, que pode estar em "schema" ou em "content";
+// no Swagger 2.0, parâmetros que não são "body" e headers declaram o tipo diretamente e são tratados como schema
+func coletarSchemasParametro(parametro *yaml.Node, ponteiro string, ctx contexto, schemas []localSchema) []localSchema {
+	if schema := valorDoMapa(parametro, "schema"); schema != nil {
+		schemas = append(schemas, localSchema{ponteiro + "/schema", schema, ctx})
+	} else if !temChave(parametro, "content") && !temChave(parametro, "$ref") && temChave(parametro, "type") {
+		schemas = append(schemas, localSchema{ponteiro, parametro, ctx})
+	}
+	return coletarSchemasConteudo(parametro, ponteiro, ctx, schemas)
+}
+
+// Função para coletar os schemas de uma response, incluindo o corpo e os headers
+func coletarSchemasResponse(response *yaml.Node, ponteiro string, schemas []localSchema) []localSchema {
+	schemas = coletarSchemasConteudo(response, ponteiro, contextoResponse, schemas)
+	// Swagger 2.0: o schema da response fica diretamente em "schema"
+	if schema := valorDoMapa(response, "schema"); schema != nil {
+		schemas = append(schemas, localSchema{ponteiro + "/schema", schema, contextoResponse})
+	}
+	if headers := valorDoMapa(response, "headers"); headers != nil {
+		for _, par := range paresDoMapa(headers) {
+			headerPonteiro := ponteiro + "/headers/" + escaparPonteiro(par.chave.Value)
+			schemas = coletarSchemasParametro(par.valor, headerPonteiro, contextoResponse, schemas)
+		}
+	}
+	return schemas
+}
+
+// Função para coletar os schemas de cada media type em "content"
+func coletarSchemasConteudo(node *yaml.Node, ponteiro string, ctx contexto, schemas []localSchema) []localSchema {
+	content := valorDoMapa(node, "content")
+	if content == nil {
+		return schemas
+	}
+	for _, par := range paresDoMapa(content) {
+		if schema := valorDoMapa(par.valor, "schema"); schema != nil {
+			mediaPonteiro := ponteiro + "/content/" + escaparPonteiro(par.chave.Value)
+			schemas = append(schemas, localSchema{mediaPonteiro + "/schema", schema, ctx})
+		}
+	}
+	return schemas
+}
+
+// Função para propagar os contextos de request/response para os schemas de componentes alcançáveis via $ref
+func propagarContextos(schemas []localSchema) {
+	componentes := make(map[string]int)
+	for i, schema := range schemas {
+		if strings.HasPrefix(schema.ponteiro, "#/components/schemas/") || strings.HasPrefix(schema.ponteiro, "#/definitions/") {
+			componentes[schema.ponteiro] = i
+		}
+	}
+
+	var pendentes []int
+	for i, schema := range schemas {
+		if schema.contexto != 0 {
+			pendentes = append(pendentes, i)
+		}
+	}
+
+	for len(pendentes) > 0 {
+		atual := schemas[pendentes[0]]
+		pendentes = pendentes[1:]
+
+		for _, ref := range coletarRefs(atual.node, nil) {
+			destino, ok := componentes[ref]
+			if !ok || schemas[destino].contexto|atual.contexto == schemas[destino].contexto {
+				continue
+			}
+			schemas[destino].contexto |= atual.contexto
+			pendentes = append(pendentes, destino)
+		}
+	}
+}
+
+// Função para coletar todos os valores de $ref dentro de um nó, seguindo aliases
+func coletarRefs(node *yaml.Node, refs []string) []string {
+	return coletarRefsVistos(node, refs, make(map[*yaml.Node]bool))
+}
+
+func coletarRefsVistos(node *yaml.Node, refs []string, vistos map[*yaml.Node]bool) []string {
+	node = resolverAlias(node)
+	if node == nil || vistos[node] {
+		return refs
+	}
+	vistos[node] = true
+	if ref, ok := texto(valorDoMapa(node, "$ref")); ok {
+		refs = append(refs, ref)
+	}
+	for _, filho := range node.Content {
+		refs = coletarRefsVistos(filho, refs, vistos)
+	}
+	return refs
+}
+
+// Função para filtrar os schemas pelo contexto da regra; regras sem contexto se aplicam a todos os schemas
+func filtrarSchemas(schemas []localSchema, ctx contexto) []localSchema {
+	if ctx == 0 {
+		return schemas
+	}
+	var filtrados []localSchema
+	for _, schema := range schemas {
+		if schema.contexto&ctx != 0 {
+			filtrados = append(filtrados, schema)
+		}
+	}
+	return filtrados
+}
+
+// palavras-chave do JSON Schema cujos valores são sub-schemas: um único schema, um mapa por nome ou uma lista
+var (
+	subschemasUnicos = []string{"items", "additionalProperties", "not", "contains", "propertyNames", "if", "then", "else"}
+	subschemasMapas  = []string{"properties", "patternProperties", "$defs", "definitions", "dependentSchemas"}
+	subschemasListas = []string{"allOf", "anyOf", "oneOf", "prefixItems"}
+)
+
+// Função para visitar um schema e todos os seus sub-schemas com o JSON pointer de cada um; $refs não são
+// seguidos, pois os schemas referenciados são visitados na sua própria localização
+func percorrerSubschemas(node *yaml.Node, ponteiro string, visita func(schema *yaml.Node, ponteiro string)) {
+	node = resolverAlias(node)
+	if node == nil || node.Kind != yaml.MappingNode || temChave(node, "$ref") {
+		return
+	}
+	visita(node, ponteiro)
+	for _, chave := range subschemasUnicos {
+		percorrerSubschemas(valorDoMapa(node, chave), ponteiro+"/"+chave, visita)
+	}
+	for _, chave := range subschemasMapas {
+		for _, par := range paresDoMapa(valorDoMapa(node, chave)) {
+			percorrerSubschemas(par.valor, ponteiro+"/"+chave+"/"+escaparPonteiro(par.chave.Value), visita)
+		}
+	}
+	for _, chave := range subschemasListas {
+		for i, item := range itensDaLista(valorDoMapa(node, chave)) {
+			percorrerSubschemas(item, ponteiro+"/"+chave+"/"+strconv.Itoa(i), visita)
+		}
+	}
+}
@@ -0,0 +1,264 @@
+package main
+
+import (
+	"fmt"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/pb33f/libopenapi/index"
+	"gopkg.in/yaml.v3"
+)
+
+// requisitoDeSeguranca é um esquema citado em um security requirement, com os escopos exigidos
+type requisitoDeSeguranca struct {
+	campo   string // JSON pointer do esquema dentro do requirement
+	esquema string
+	escopos []string
+}
+
+// exigenciaDeSeguranca é a lista de security requirements efetiva de uma operação: a da operação ou,
+// na ausência dela, a da raiz do documento
+type exigenciaDeSeguranca struct {
+	campo        string // JSON pointer da lista; vazio quando nenhuma das duas declara security
+	alternativas [][]requisitoDeSeguranca
+}
+
+// Função para ler uma lista de security requirements; cada item é uma alternativa e os esquemas de um item
+// são exigidos em conjunto
+func lerExigencia(lista *yaml.Node, campo string) exigenciaDeSeguranca {
+	exigencia := exigenciaDeSeguranca{campo: campo}
+	for i, item := range itensDaLista(lista) {
+		alternativa := []requisitoDeSeguranca{}
+		for _, par := range paresDoMapa(item) {
+			alternativa = append(alternativa, requisitoDeSeguranca{
+				campo:   campo + "/" + strconv.Itoa(i) + "/" + escaparPonteiro(par.chave.Value),
+				esquema: par.chave.Value,
+				escopos: textosDaLista(par.valor),
+			})
+		}
+		exigencia.alternativas = append(exigencia.alternativas, alternativa)
+	}
+	return exigencia
+}
+
+// Função para obter a exigência de segurança efetiva da operação
+func exigenciaDaOperacao(doc *yaml.Node, op operacao) exigenciaDeSeguranca {
+	if lista := valorDoMapa(op.node, "security"); lista != nil {
+		return lerExigencia(lista, op.ponteiro+"/security")
+	}
+	if lista := valorDoMapa(doc, "security"); lista != nil {
+		return lerExigencia(lista, "#/security")
+	}
+	return exigenciaDeSeguranca{}
+}
+
+// Função para listar os requirements declarados na raiz e nas operações, sem repetir a lista da raiz
+func requisitosDoDocumento(doc *yaml.Node) []requisitoDeSeguranca {
+	var requisitos []requisitoDeSeguranca
+	exigencias := []exigenciaDeSeguranca{lerExigencia(valorDoMapa(doc, "security"), "#/security")}
+	for _, op := range listarOperacoes(doc) {
+		if lista := valorDoMapa(op.node, "security"); lista != nil {
+			exigencias = append(exigencias, lerExigencia(lista, op.ponteiro+"/security"))
+		}
+	}
+	for _, exigencia := range exigencias {
+		for _, alternativa := range exigencia.alternativas {
+			requisitos = append(requisitos, alternativa...)
+		}
+	}
+	return requisitos
+}
+
+// Função para obter o mapa de esquemas de segurança: securityDefinitions no Swagger 2.0 e
+// components.securitySchemes no OpenAPI 3
+func esquemasDeSeguranca(doc *yaml.Node) (*yaml.Node, string) {
+	if versao, _ := detectarVersao(doc); versao == versaoOAS2 {
+		return valorDoMapa(doc, "securityDefinitions"), "#/securityDefinitions"
+	}
+	return valorDoMapa(valorDoMapa(doc, "components"), "securitySchemes"), "#/components/securitySchemes"
+}
+
+// Função para buscar um esquema de segurança pelo nome, seguindo $ref
+func buscarEsquema(doc *yaml.Node, idx *index.SpecIndex, nome string) (*yaml.Node, string) {
+	esquemas, ponteiro := esquemasDeSeguranca(doc)
+	node := valorDoMapa(esquemas, nome)
+	if node == nil {
+		return nil, ""
+	}
+	campo := ponteiro + "/" + escaparPonteiro(nome)
+	node, origem := derreferenciar(idx, node)
+	if origem != "" {
+		campo = origem
+	}
+	return node, campo
+}
+
+// Função para listar os fluxos OAuth2 do esquema, pelo JSON pointer de cada um; no Swagger 2.0 o próprio
+// esquema é o único fluxo
+func fluxosDoEsquema(esquema *yaml.Node, campo string) map[string]*yaml.Node {
+	fluxos := make(map[string]*yaml.Node)
+	if tipo, _ := texto(valorDoMapa(esquema, "type")); tipo != "oauth2" {
+		return fluxos
+	}
+	if !temChave(esquema, "flows") {
+		fluxos[campo] = esquema
+		return fluxos
+	}
+	for _, par := range paresDoMapa(valorDoMapa(esquema, "flows")) {
+		fluxos[campo+"/flows/"+escaparPonteiro(par.chave.Value)] = resolverAlias(par.valor)
+	}
+	return fluxos
+}
+
+// Função para listar os escopos declarados nos fluxos OAuth2 do esquema
+func escoposDoEsquema(esquema *yaml.Node, campo string) map[string]bool {
+	escopos := make(map[string]bool)
+	for _, fluxo := range fluxosDoEsquema(esquema, campo) {
+		for _, par := range paresDoMapa(valorDoMapa(fluxo, "scopes")) {
+			escopos[par.chave.Value] = true
+		}
+	}
+	return escopos
+}
+
+// Função para validar se cada operação exige autenticação; uma lista vazia ou uma alternativa vazia ({})
+// torna a operação acessível sem credenciais
+func validarSegurancaDasOperacoes(doc *yaml.Node, validationErrors *[]error, r regra) []error {
+	for _, op := range listarOperacoes(doc) {
+		operacao := strings.ToUpper(op.metodo) + " " + op.path
+		exigencia := exigenciaDaOperacao(doc, op)
+		switch {
+		case exigencia.campo == "":
+			*validationErrors = append(*validationErrors, r.findingDetalhado(op.ponteiro,
+				fmt.Sprintf("%s não declara security, nem há security na raiz do documento.", operacao)))
+		case len(exigencia.alternativas) == 0:
+			*validationErrors = append(*validationErrors, r.findingDetalhado(exigencia.campo,
+				fmt.Sprintf("%s declara uma lista de security vazia e não exige autenticação.", operacao)))
+		default:
+			for i, alternativa := range exigencia.alternativas {
+				if len(alternativa) == 0 {
+					*validationErrors = append(*validationErrors, r.findingDetalhado(exigencia.campo+"/"+strconv.Itoa(i),
+						fmt.Sprintf("%s aceita requisições sem autenticação pela alternativa vazia {}.", operacao)))
+				}
+			}
+		}
+	}
+	return *validationErrors
+}
+
+// Função para validar se os esquemas citados nos security requirements estão declarados
+func validarEsquemasReferenciados(doc *yaml.Node, idx *index.SpecIndex, validationErrors *[]error, r regra) []error {
+	_, ponteiro := esquemasDeSeguranca(doc)
+	for _, requisito := range requisitosDoDocumento(doc) {
+		if esquema, _ := buscarEsquema(doc, idx, requisito.esquema); esquema == nil {
+			*validationErrors = append(*validationErrors, r.findingDetalhado(requisito.campo,
+				fmt.Sprintf("O esquema de segurança %s não está declarado em %s.", requisito.esquema, ponteiro)))
+		}
+	}
+	return *validationErrors
+}
+
+// Função para validar se os escopos exigidos estão declarados nos fluxos do esquema OAuth2; esquemas de
+// outros tipos não declaram escopos (openIdConnect os obtém do discovery)
+func validarEscoposDeclarados(doc *yaml.Node, idx *index.SpecIndex, validationErrors *[]error, r regra) []error {
+	for _, requisito := range requisitosDoDocumento(doc) {
+		esquema, campo := buscarEsquema(doc, idx, requisito.esquema)
+		if tipo, _ := texto(valorDoMapa(esquema, "type")); tipo != "oauth2" {
+			continue
+		}
+		declarados := escoposDoEsquema(esquema, campo)
+		var ausentes []string
+		for _, escopo := range requisito.escopos {
+			if !declarados[escopo] {
+				ausentes = append(ausentes, escopo)
+			}
+		}
+		if len(ausentes) > 0 {
+			*validationErrors = append(*validationErrors, r.findingDetalhado(requisito.campo,
+				fmt.Sprintf("Escopos não declarados nos fluxos de %s: %s.", requisito.esquema, strings.Join(ausentes, ", "))))
+		}
+	}
+	return *validationErrors
+}
+
+// Função para validar se as URLs dos fluxos OAuth2 e do discovery OpenID Connect usam HTTPS
+func validarURLsOAuth2(doc *yaml.Node, idx *index.SpecIndex, validationErrors *[]error, r regra) []error {
+	esquemas, _ := esquemasDeSeguranca(doc)
+	for _, par := range paresDoMapa(esquemas) {
+		esquema, campo := buscarEsquema(doc, idx, par.chave.Value)
+		locais := fluxosDoEsquema(esquema, campo)
+		if tipo, _ := texto(valorDoMapa(esquema, "type")); tipo == "openIdConnect" {
+			locais[campo] = esquema
+		}
+		var ponteiros []string
+		for ponteiro := range locais {
+			ponteiros = append(ponteiros, ponteiro)
+		}
+		sort.Strings(ponteiros)
+		for _, ponteiro := range ponteiros {
+			for _, chave := range []string{"authorizationUrl", "tokenUrl", "refreshUrl", "openIdConnectUrl"} {
+				url, ok := texto(valorDoMapa(locais[ponteiro], chave))
+				if ok && !strings.HasPrefix(strings.TrimSpace(url), "https://") {
+					*validationErrors = append(*validationErrors, r.findingDetalhado(ponteiro+"/"+chave,
+						fmt.Sprintf("A URL %s %q de %s não usa HTTPS.", chave, url, par.chave.Value)))
+				}
+			}
+		}
+	}
+	return *validationErrors
+}
+
+// Função para verificar se o texto menciona o escopo como um termo isolado, e não como parte de um path
+// (ex.: recurring-payments em /pix/recurring-payments)
+func textoMencionaEscopo(texto, escopo string) bool {
+	re := regexp.MustCompile(`(^|[^A-Za-z0-9_:/{}.-])` + regexp.QuoteMeta(escopo) + `([^A-Za-z0-9_:/{}-]|$)`)
+	return re.MatchString(texto)
+}
+
+// Função para comparar os escopos mencionados no info.description com os exigidos pelas operações; apenas
+// os escopos declarados nos esquemas são procurados no texto, pois os não declarados já são reportados
+// por security-scopes-declared
+func validarEscoposDaDescricao(doc *yaml.Node, idx *index.SpecIndex, validationErrors *[]error, r regra) []error {
+	descricao, _ := texto(valorDoMapa(valorDoMapa(doc, "info"), "description"))
+	ignorados := textosDaOpcao(r.opcoes()["ignoredScopes"])
+
+	declarados := make(map[string]bool)
+	esquemas, _ := esquemasDeSeguranca(doc)
+	for _, par := range paresDoMapa(esquemas) {
+		esquema, campo := buscarEsquema(doc, idx, par.chave.Value)
+		for escopo := range escoposDoEsquema(esquema, campo) {
+			declarados[escopo] = true
+		}
+	}
+	exigidos := make(map[string]bool)
+	for _, requisito := range requisitosDoDocumento(doc) {
+		for _, escopo := range requisito.escopos {
+			exigidos[escopo] = true
+		}
+	}
+
+	var semMencao, semUso []string
+	for escopo := range exigidos {
+		if declarados[escopo] && !contemTexto(ignorados, escopo) && !textoMencionaEscopo(descricao, escopo) {
+			semMencao = append(semMencao, escopo)
+		}
+	}
+	for escopo := range declarados {
+		if !exigidos[escopo] && !contemTexto(ignorados, escopo) && textoMencionaEscopo(descricao, escopo) {
+			semUso = append(semUso, escopo)
+		}
+	}
+	sort.Strings(semMencao)
+	sort.Strings(semUso)
+	if len(semMencao) > 0 {
+		*validationErrors = append(*validationErrors, r.findingDetalhado("#/info/description",
+			fmt.Sprintf("Escopos exigidos pelas operações e não mencionados na descrição: %s.", strings.Join(semMencao, ", "))))
+	}
+	if len(semUso) > 0 {
+		*validationErrors = append(*validationErrors, r.findingDetalhado("#/info/description",
+			fmt.Sprintf("Escopos mencionados na descrição e não exigidos por nenhuma operação: %s.", strings.Join(semUso, ", "))))
+	}
+	return *validationErrors
+}
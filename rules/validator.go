@@ -41,7 +41,7 @@ func readFile(filePath string) ([]byte, error) {
 	return utf8Data, nil
 }
 
-// Função para carregar as regras personalizadas do pb33f_rules.yaml
+// Função para carregar as regras personalizadas do pb33f_rules.yaml, incluindo os pacotes de "extends"
 func loadRules(ruleFile string) (map[string]interface{}, error) {
 	data, err := ioutil.ReadFile(ruleFile)
 	if err != nil {
@@ -52,510 +52,622 @@ func loadRules(ruleFile string) (map[string]interface{}, error) {
 	if err := yaml.Unmarshal(data, &rules); err != nil {
 		return nil, fmt.Errorf("erro ao fazer unmarshal das regras: %v", err)
 	}
+	if err := estenderRegras(rules, ruleFile, make(map[string]bool)); err != nil {
+		return nil, err
+	}
 
 	return rules, nil
 }
 
-// Função para validar um arquivo OpenAPI usando regras personalizadas
-func validateOpenAPIWithRules(filePath string, rulesFile string) error {
+// Função para validar um arquivo OpenAPI usando regras personalizadas; as opções indicam onde encontrar
+// os arquivos e documentos remotos referenciados, e o arquivo anterior (opcional) é a versão anterior do
+// documento, usada pelas regras que comparam as duas versões
+func validateOpenAPIWithRules(filePath string, rulesFile string, opcoes opcoesReferencias, arquivoAnterior string) error {
 	// Ler o arquivo OpenAPI e converter para UTF-8
 	data, err := readFile(filePath)
 	if err != nil {
 		return err
 	}
 
-	// Criar um nó YAML a partir do arquivo
-	var rootNode yaml.Node
-	if err := yaml.Unmarshal(data, &rootNode); err != nil {
-		return fmt.Errorf("erro ao fazer unmarshal do YAML: %v", err)
+	// Carregar regras personalizadas
+	rules, err := loadRules(rulesFile)
+	if err != nil {
+		return err
 	}
 
-	// Criar configuração de indexação
-	indexConfig := index.CreateClosedAPIIndexConfig()
-
-	// Criar um indexador para a especificação OpenAPI
-	idx := index.NewSpecIndexWithConfig(&rootNode, indexConfig)
-	// Obter erros básicos do OpenAPI
-	validationErrors := idx.GetReferenceIndexErrors()
+	// Ler a versão anterior do documento, quando informada
+	var anterior *yaml.Node
+	if arquivoAnterior != "" {
+		dataAnterior, err := readFile(arquivoAnterior)
+		if err != nil {
+			return err
+		}
+		if anterior, _, err = lerDocumento(dataAnterior); err != nil {
+			return fmt.Errorf("erro ao ler a versão anterior %s: %v", arquivoAnterior, err)
+		}
+	}
 
-	// Carregar regras personalizadas
-	rules, err := loadRules(rulesFile)
+	validationErrors, err := validarDocumento(data, filePath, rules, anterior, opcoes)
 	if err != nil {
 		return err
 	}
 
+	// Exibir erros encontrados
+	if len(validationErrors) > 0 {
+		var onlyWarn = true
+		for _, err := range validationErrors {
+			if isErro(err) {
+				onlyWarn = false
+			}
+
+			fmt.Println("❌ Erro de validação:", err)
+		}
+		if !onlyWarn {
+			return fmt.Errorf("falha na validação do OpenAPI")
+		}
+	}
+
+	fmt.Println("✅ OpenAPI válido com regras aplicadas:", filePath)
+	return nil
+}
+
+// Função para interpretar o conteúdo de um arquivo OpenAPI e aplicar as regras, retornando os findings já
+// localizados; documentos inválidos ou vazios viram um finding de parse
+func validarDocumento(data []byte, filePath string, rules map[string]interface{}, anterior *yaml.Node, opcoes opcoesReferencias) ([]error, error) {
+	// Criar um nó YAML a partir do arquivo (YAML ou JSON)
+	rootNode, _, err := lerDocumento(data)
+	if err != nil {
+		return []error{findingDeParse(err.Error())}, nil
+	}
+	if documento(rootNode) == nil {
+		return []error{findingDeParse("o documento está vazio ou não é um objeto OpenAPI")}, nil
+	}
+	rolodex, err := novoRolodex(rootNode, filePath, opcoes)
+	if err != nil {
+		return nil, err
+	}
+	validationErrors := aplicarRegras(rootNode, anterior, rules, rolodex, opcoes.circulares)
+	localizarFindings(rootNode, validationErrors)
+	localizarFindingsExternos(rolodex, validationErrors)
+	return validationErrors, nil
+}
+
+// Função para indexar o documento e aplicar todas as regras personalizadas; a política define a severidade
+// das referências circulares e o anterior é a versão anterior do documento, ou nil
+func aplicarRegras(rootNode, anterior *yaml.Node, rules map[string]interface{}, rolodex *index.Rolodex, politica politicaCircular) []error {
+	// Usar o índice do documento principal; os arquivos referenciados ficam nos demais índices do rolodex
+	idx := rolodex.GetRootIndex()
+	// Obter erros básicos do OpenAPI, inclusive de arquivos referenciados ausentes ou ilegíveis
+	validationErrors := errosDeReferencia(rolodex)
+
+	// Detectar referências circulares, que os validadores de schema não percorrem mais de uma vez
+	validationErrors = append(validationErrors, validarReferenciasCirculares(idx, politica)...)
+
+	// Verificar a versão da especificação; regras com "formats" só se aplicam às versões listadas
+	versao, _ := detectarVersao(documento(rootNode))
+	validationErrors = append(validationErrors, validarVersao(documento(rootNode))...)
+	validationErrors = append(validationErrors, validarEstrutura(documento(rootNode))...)
+
+	// Coletar todos os schemas do documento, inclusive os definidos inline nas operações
+	schemas := coletarSchemas(rootNode)
+
 	// Aplicar regras personalizadas
 	if rulesMap, ok := rules["rules"].(map[string]interface{}); ok {
 		for ruleName, rule := range rulesMap {
-			ruleData := rule.(map[string]interface{})
+			// regras de pacotes podem ser desligadas no arquivo com "off" ou false
+			if desligada, ok := rule.(string); (ok && desligada == "off") || rule == false {
+				continue
+			}
+			ruleData, _ := rule.(map[string]interface{})
 			// given := ruleData["given"].(string)
 			fmt.Println(ruleName)
-			severity := ruleData["severity"].(string)
-
-			// Aplicação manual de regras
-			if ruleName == "enforce-security" {
-				if idx.GetAllSecuritySchemes() == nil {
-					validationErrors = append(validationErrors, fmt.Errorf("[%s] %s", severity, ruleData["description"]))
-				}
+			r, err := novaRegra(ruleName, ruleData)
+			if err != nil {
+				validationErrors = append(validationErrors, err)
+				continue
 			}
-
-			if ruleName == "openapi-tags" {
-				if idx.GetTotalTagsCount() == 0 {
-					validationErrors = append(validationErrors, fmt.Errorf("[%s] %s", severity, ruleData["description"]))
-				}
+			if !r.aplicaA(versao) {
+				continue
 			}
+			validationErrors = append(validationErrors, aplicarRegra(r, rootNode, anterior, idx, schemas)...)
+		}
+	}
 
-			if ruleName == "require-contact-info" {
-				validationErrors = validarInfo(rootNode.Content[0], &validationErrors, severity, ruleData, "contact")
-			}
+	return validationErrors
+}
 
-			if ruleName == "info-title" {
-				validationErrors = validarInfo(rootNode.Content[0], &validationErrors, severity, ruleData, "title")
-			}
+// tipos esperados dos campos da raiz do documento; as regras ignoram os campos com outro tipo, então eles
+// são reportados aqui para que não passem despercebidos
+var tiposDaRaiz = []struct {
+	chave string
+	tipo  yaml.Kind
+	nome  string
+}{
+	{"info", yaml.MappingNode, "um objeto"},
+	{"paths", yaml.MappingNode, "um objeto"},
+	{"components", yaml.MappingNode, "um objeto"},
+	{"definitions", yaml.MappingNode, "um objeto"},
+	{"securityDefinitions", yaml.MappingNode, "um objeto"},
+	{"webhooks", yaml.MappingNode, "um objeto"},
+	{"servers", yaml.SequenceNode, "uma lista"},
+	{"tags", yaml.SequenceNode, "uma lista"},
+	{"security", yaml.SequenceNode, "uma lista"},
+}
 
-			if ruleName == "info-description" {
-				validationErrors = validarInfo(rootNode.Content[0], &validationErrors, severity, ruleData, "description")
-			}
+// Função para validar os tipos dos campos da raiz do documento
+func validarEstrutura(doc *yaml.Node) []error {
+	var validationErrors []error
+	for _, campo := range tiposDaRaiz {
+		node := valorDoMapa(doc, campo.chave)
+		if node == nil || node.Kind == campo.tipo || (node.Kind == yaml.ScalarNode && node.Tag == "!!null") {
+			continue
+		}
+		validationErrors = append(validationErrors, &finding{
+			regra:      "spec-structure",
+			severidade: "error",
+			campo:      "#/" + campo.chave,
+			descricao:  fmt.Sprintf("O campo `%s` deve ser %s", campo.chave, campo.nome),
+		})
+	}
+	return validationErrors
+}
 
-			if ruleName == "info-version" {
-				var infoVal string
-
-				aquivoNode := rootNode.Content[0]
-				for i := 0; i < len(aquivoNode.Content); i += 2 {
-					nodeArquivo := aquivoNode.Content[i]
-					if nodeArquivo.Kind == yaml.ScalarNode && nodeArquivo.Value == "info" {
-						infoNode := aquivoNode.Content[i+1]
-						for j := 0; j < len(infoNode.Content); j += 2 {
-							nodeInfo := infoNode.Content[j]
-							if nodeInfo.Kind == yaml.ScalarNode && nodeInfo.Value == "version" {
-								infoVal = infoNode.Content[j+1].Value
-							}
-						}
-					}
-				}
-
-				re := regexp.MustCompile(`^(\d+\.\d+\.\d+)(?:-(rc|beta)\.\d+)?$`)
-				if !re.MatchString(strings.TrimSpace(string(infoVal))) {
-					validationErrors = append(validationErrors, fmt.Errorf("[%s] %s", severity, ruleData["description"]))
-				}
-			}
+// Função para aplicar uma regra; um panic durante a regra é convertido em finding para não interromper as demais
+func aplicarRegra(r regra, rootNode, anterior *yaml.Node, idx *index.SpecIndex, schemas []localSchema) (validationErrors []error) {
+	defer func() {
+		if recuperado := recover(); recuperado != nil {
+			validationErrors = append(validationErrors, &finding{
+				regra:      r.nome,
+				severidade: "error",
+				descricao:  fmt.Sprintf("falha interna ao aplicar a regra: %v", recuperado),
+			})
+		}
+	}()
 
-			if ruleName == "only-https" {
-				servers := idx.GetAllRootServers()
-				for i := 0; i < len(servers); i += 1 {
-					infoVal, _ := yaml.Marshal(servers[i].Node.Content[1])
-					url := strings.Trim(strings.TrimSpace(string(infoVal)), "'")
+	doc := documento(rootNode)
+	schemasRegra := filtrarSchemas(schemas, r.contexto)
+	p := novoPercurso(idx, schemasRegra)
 
-					if !strings.HasPrefix(url, "https://") {
-						validationErrors = append(validationErrors, fmt.Errorf("[%s] %s", severity, ruleData["description"]))
-					}
-				}
-			}
+	// validarSchemas aplica um validador recursivo em todos os schemas do contexto da regra
+	validarSchemas := func(validar func(schema *yaml.Node, campo string)) {
+		for _, schema := range schemasRegra {
+			p.visitar(schema.node, p.campo(schema.node, schema.ponteiro), validar)
+		}
+	}
 
-			if ruleName == "paths-kebab-case" {
-				for path := range idx.GetAllPaths() {
+	// Aplicação manual de regras
+	switch r.nome {
+	case "enforce-security":
+		if idx.GetAllSecuritySchemes() == nil {
+			validationErrors = append(validationErrors, r.finding(""))
+		}
 
-					re := regexp.MustCompile(`\{[^}]+\}`)
-					pathNoVariable := re.ReplaceAllString(path, "")
-					kebabRegex := regexp.MustCompile(`^\/([a-z0-9]+(-[a-z0-9]+)*)+(\/[a-z0-9]+(-[a-z0-9]+)*)*\/?$`)
+	case "operation-security":
+		validationErrors = validarSegurancaDasOperacoes(doc, &validationErrors, r)
 
-					if !kebabRegex.MatchString(pathNoVariable) {
-						validationErrors = append(validationErrors, fmt.Errorf("[%s] %s", severity, ruleData["description"]))
-					}
+	case "security-schemes-defined":
+		validationErrors = validarEsquemasReferenciados(doc, idx, &validationErrors, r)
 
-				}
-			}
+	case "security-scopes-declared":
+		validationErrors = validarEscoposDeclarados(doc, idx, &validationErrors, r)
 
-			if ruleName == "operation-operationId" {
-				validationErrors = validarPaths(idx.GetAllPaths(), &validationErrors, severity, ruleData, "operationId")
-			}
+	case "oauth2-https-urls":
+		validationErrors = validarURLsOAuth2(doc, idx, &validationErrors, r)
 
-			if ruleName == "operation-tags" {
-				validationErrors = validarPaths(idx.GetAllPaths(), &validationErrors, severity, ruleData, "tags")
-			}
+	case "ofb-description-scopes":
+		validationErrors = validarEscoposDaDescricao(doc, idx, &validationErrors, r)
 
-			if ruleName == "pattern-found-NA" {
-				for schema := range idx.GetAllComponentSchemas() {
-					schemaValidado := idx.GetAllComponentSchemas()[schema].Node.Content
-					validationErrors = validarPattern(schemaValidado, &validationErrors, severity, ruleData, `\bNA\b`, schema)
-				}
-			}
+	case "openapi-tags":
+		if idx.GetTotalTagsCount() == 0 {
+			validationErrors = append(validationErrors, r.finding(""))
+		}
 
-			if ruleName == "pattern-found-texto" {
-				for schema := range idx.GetAllComponentSchemas() {
-					schemaValidado := idx.GetAllComponentSchemas()[schema].Node.Content
-					validationErrors = validarPattern(schemaValidado, &validationErrors, severity, ruleData, `\\w*\\W*`, schema)
-				}
-			}
+	case "require-contact-info":
+		validationErrors = validarInfo(doc, &validationErrors, r, "contact")
 
-			if ruleName == "transaction-found-last" {
-				allComponents := idx.GetAllComponentSchemas()
-				for schema := range allComponents {
-					schemas := strings.Split(schema, "/")
-					if schemas[len(schemas)-1] == "TransactionsLinks" {
-						transactionsLinks := allComponents[schema].Node.Content
-						for i := 0; i < len(transactionsLinks); i += 2 {
-							if transactionsLinks[i].Value == "properties" {
-								properties := transactionsLinks[i+1].Content
-								for j := 0; j < len(properties); j += 2 {
-									if properties[j].Value == "last" {
-										validationErrors = append(validationErrors, fmt.Errorf("[%s] %s", severity, ruleData["description"]))
-									}
-								}
-							}
-						}
-					}
-				}
-			}
+	case "info-title":
+		validationErrors = validarInfo(doc, &validationErrors, r, "title")
 
-			//biblioteca ja retorna o valor com trim, então não consegue fazer esta validação
-			if ruleName == "no-leading-trailing-spaces" {
-				for schema := range idx.GetAllComponentSchemas() {
-					schemaValidado := idx.GetAllComponentSchemas()[schema].Node.Content
-					validationErrors = validarPatternString(schemaValidado, &validationErrors, severity, ruleData, schema)
-				}
-			}
+	case "info-description":
+		validationErrors = validarInfo(doc, &validationErrors, r, "description")
 
-			//também valida objects-required-in-request-should-has-properties-response
-			if ruleName == "objects-required-in-request-should-has-properties-request" {
-				for schema := range idx.GetAllComponentSchemas() {
-					schemaValidado := idx.GetAllComponentSchemas()[schema].Node.Content
-					validationErrors = validarObjeto(schemaValidado, &validationErrors, severity, ruleData, schema)
-				}
-			}
+	case "info-version":
+		infoVal, _ := texto(valorDoMapa(valorDoMapa(doc, "info"), "version"))
 
-			if ruleName == "string-should-has-maxLength" {
-				for schema := range idx.GetAllComponentSchemas() {
-					schemaValidado := idx.GetAllComponentSchemas()[schema].Node.Content
-					validationErrors = validarPropriedadeString(schemaValidado, &validationErrors, severity, ruleData, "maxLength", schema)
-				}
-			}
+		re := regexp.MustCompile(`^(\d+\.\d+\.\d+)(?:-(rc|beta)\.\d+)?$`)
+		if !re.MatchString(strings.TrimSpace(infoVal)) {
+			validationErrors = append(validationErrors, r.finding(""))
+		}
 
-			if ruleName == "string-should-has-minLength" {
-				for schema := range idx.GetAllComponentSchemas() {
-					schemaValidado := idx.GetAllComponentSchemas()[schema].Node.Content
-					validationErrors = validarPropriedadeString(schemaValidado, &validationErrors, severity, ruleData, "minLength", schema)
-				}
-			}
+	case "only-https":
+		for _, server := range idx.GetAllRootServers() {
+			url, _ := texto(valorDoMapa(server.Node, "url"))
 
-			if ruleName == "string-should-has-pattern" {
-				for schema := range idx.GetAllComponentSchemas() {
-					schemaValidado := idx.GetAllComponentSchemas()[schema].Node.Content
-					validationErrors = validarPropriedadeString(schemaValidado, &validationErrors, severity, ruleData, "pattern", schema)
-				}
+			if !strings.HasPrefix(strings.TrimSpace(url), "https://") {
+				validationErrors = append(validationErrors, r.finding(""))
 			}
+		}
 
-			if ruleName == "no-maxLentgh-for-enum" {
-				for schema := range idx.GetAllComponentSchemas() {
-					schemaValidado := idx.GetAllComponentSchemas()[schema].Node.Content
-					validationErrors = validarPropriedadeEnum(schemaValidado, &validationErrors, severity, ruleData, "maxLength", schema)
-				}
-			}
+	case "paths-kebab-case":
+		re := regexp.MustCompile(`\{[^}]+\}`)
+		kebabRegex := regexp.MustCompile(`^\/([a-z0-9]+(-[a-z0-9]+)*)+(\/[a-z0-9]+(-[a-z0-9]+)*)*\/?$`)
+		for path := range idx.GetAllPaths() {
+			pathNoVariable := re.ReplaceAllString(path, "")
 
-			if ruleName == "no-minLength-for-enum" {
-				for schema := range idx.GetAllComponentSchemas() {
-					schemaValidado := idx.GetAllComponentSchemas()[schema].Node.Content
-					validationErrors = validarPropriedadeEnum(schemaValidado, &validationErrors, severity, ruleData, "minLength", schema)
-				}
+			if !kebabRegex.MatchString(pathNoVariable) {
+				validationErrors = append(validationErrors, r.finding(""))
 			}
+		}
+
+	case "operation-operationId":
+		validationErrors = validarPaths(idx.GetAllPaths(), &validationErrors, r, "operationId")
 
-			if ruleName == "array-objects-max-items" {
-				for schema := range idx.GetAllComponentSchemas() {
-					schemaValidado := idx.GetAllComponentSchemas()[schema].Node.Content
-					validationErrors = validarArrayMaxItems(schemaValidado, &validationErrors, severity, ruleData, schema)
-				}
+	case "operation-tags":
+		validationErrors = validarPaths(idx.GetAllPaths(), &validationErrors, r, "tags")
+
+	case "pattern-found-NA":
+		validarSchemas(func(schema *yaml.Node, campo string) {
+			validationErrors = validarPattern(schema, &validationErrors, p, r, `\bNA\b`, campo)
+		})
+
+	case "pattern-found-texto":
+		validarSchemas(func(schema *yaml.Node, campo string) {
+			validationErrors = validarPattern(schema, &validationErrors, p, r, `\\w\*\\W\*`, campo)
+		})
+
+	case "transaction-found-last":
+		for nome, schema := range idx.GetAllComponentSchemas() {
+			partes := strings.Split(nome, "/")
+			if partes[len(partes)-1] == "TransactionsLinks" && temChave(valorDoMapa(schema.Node, "properties"), "last") {
+				validationErrors = append(validationErrors, r.finding(""))
 			}
 		}
-	}
 
-	// Exibir erros encontrados
-	if len(validationErrors) > 0 {
-		var onlyWarn = true
-		for _, err := range validationErrors {
-			re := regexp.MustCompile(`\berror\b`)
+	//biblioteca ja retorna o valor com trim, então não consegue fazer esta validação
+	case "no-leading-trailing-spaces":
+		validarSchemas(func(schema *yaml.Node, campo string) {
+			validationErrors = validarPatternString(schema, &validationErrors, p, r, campo)
+		})
+
+	case "objects-required-in-request-should-has-properties-request", "objects-required-in-request-should-has-properties-response":
+		validarSchemas(func(schema *yaml.Node, campo string) {
+			validationErrors = validarObjeto(schema, &validationErrors, p, r, campo)
+		})
+
+	case "string-should-has-maxLength":
+		validarSchemas(func(schema *yaml.Node, campo string) {
+			validationErrors = validarPropriedadeString(schema, &validationErrors, p, r, "maxLength", campo)
+		})
+
+	case "string-should-has-minLength":
+		validarSchemas(func(schema *yaml.Node, campo string) {
+			validationErrors = validarPropriedadeString(schema, &validationErrors, p, r, "minLength", campo)
+		})
+
+	case "string-should-has-pattern":
+		validarSchemas(func(schema *yaml.Node, campo string) {
+			validationErrors = validarPropriedadeString(schema, &validationErrors, p, r, "pattern", campo)
+		})
+
+	case "no-maxLength-for-enum", "no-maxLentgh-for-enum":
+		validarSchemas(func(schema *yaml.Node, campo string) {
+			validationErrors = validarPropriedadeEnum(schema, &validationErrors, p, r, "maxLength", campo)
+		})
+
+	case "no-minLength-for-enum", "no-minLentgh-for-enum":
+		validarSchemas(func(schema *yaml.Node, campo string) {
+			validationErrors = validarPropriedadeEnum(schema, &validationErrors, p, r, "minLength", campo)
+		})
+
+	case "no-unused-components":
+		for _, c := range componentesNaoUsados(rootNode, idx) {
+			validationErrors = append(validationErrors, r.finding(c.ponteiro))
+		}
 
-			if re.MatchString(err.Error()) {
-				onlyWarn = false
-			}
-			
-			fmt.Println("❌ Erro de validação:", err)
+	case "no-duplicate-schemas":
+		validationErrors = validarSchemasDuplicados(schemas, &validationErrors, r)
+
+	case "ofb-request-headers":
+		validationErrors = validarHeadersRequisicao(doc, idx, &validationErrors, r)
+
+	case "ofb-response-headers":
+		validationErrors = validarHeadersResposta(doc, idx, &validationErrors, r)
+
+	case "ofb-header-schemas":
+		validationErrors = validarSchemasHeaders(doc, idx, &validationErrors, r)
+
+	case "ofb-idempotency-key":
+		validationErrors = validarChaveIdempotencia(doc, idx, &validationErrors, r)
+
+	case "ofb-error-response-schema":
+		validationErrors = validarRespostasDeErro(doc, idx, &validationErrors, r)
+
+	case "ofb-422-error-codes":
+		validationErrors = validarCodigosEnumerados(doc, idx, &validationErrors, r)
+
+	case "ofb-error-codes-documented":
+		validationErrors = validarCodigosDocumentados(doc, idx, &validationErrors, r)
+
+	case "ofb-pagination":
+		validationErrors = validarPaginacao(doc, idx, &validationErrors, r)
+
+	case "format-pattern-consistency":
+		formatos := textosDaOpcao(r.opcoes()["formats"])
+		validarSchemas(func(schema *yaml.Node, campo string) {
+			validationErrors = validarFormatoPattern(schema, &validationErrors, p, r, formatos, campo)
+		})
+
+	case "ofb-date-time-utc":
+		validarSchemas(func(schema *yaml.Node, campo string) {
+			validationErrors = validarDateTimeUTC(schema, &validationErrors, p, r, campo)
+		})
+
+	case "br-identifiers":
+		identificadores, err := identificadoresDasOpcoes(r.opcoes())
+		if err != nil {
+			validationErrors = append(validationErrors, r.findingDetalhado("", err.Error()))
+			break
 		}
-		if !onlyWarn {
-			return fmt.Errorf("falha na validação do OpenAPI")
+		genericas := textosDaOpcao(r.opcoes()["genericProperties"])
+		validarSchemas(func(schema *yaml.Node, campo string) {
+			validationErrors = validarIdentificador(schema, &validationErrors, p, r, identificadores, genericas, campo)
+		})
+
+	case "ofb-monetary-fields":
+		convencoes, err := convencoesMonetarias(r.opcoes())
+		if err != nil {
+			validationErrors = append(validationErrors, r.findingDetalhado("", err.Error()))
+			break
 		}
+		validarSchemas(func(schema *yaml.Node, campo string) {
+			validationErrors = validarCamposMonetarios(schema, &validationErrors, p, r, convencoes, campo)
+		})
+
+	case "valid-examples":
+		validationErrors = validarExemplos(rootNode, idx, schemas, &validationErrors, r)
+
+	case "pattern-quality":
+		amostras := textosDaOpcao(r.opcoes()["arbitrarySamples"])
+		validarSchemas(func(schema *yaml.Node, campo string) {
+			validationErrors = validarQualidadePattern(schema, &validationErrors, p, r, amostras, campo)
+		})
+
+	case "enum-casing":
+		re, estilo, err := estiloDeEnum(r.opcoes())
+		if err != nil {
+			validationErrors = append(validationErrors, r.findingDetalhado("", err.Error()))
+			break
+		}
+		validarSchemas(func(schema *yaml.Node, campo string) {
+			validationErrors = validarEnums(schema, &validationErrors, p, r, campo, verificarEstiloEnum(re, estilo))
+		})
+
+	case "enum-duplicates":
+		validarSchemas(func(schema *yaml.Node, campo string) {
+			validationErrors = validarEnums(schema, &validationErrors, p, r, campo, verificarEnumDuplicado)
+		})
+
+	case "enum-type":
+		validarSchemas(func(schema *yaml.Node, campo string) {
+			validationErrors = validarEnums(schema, &validationErrors, p, r, campo, verificarTipoEnum)
+		})
+
+	case "enum-size":
+		maxValores, _ := r.opcoes()["maxValues"].(int)
+		validarSchemas(func(schema *yaml.Node, campo string) {
+			validationErrors = validarEnums(schema, &validationErrors, p, r, campo, verificarTamanhoEnum(maxValores))
+		})
+
+	case "enum-documented":
+		validarSchemas(func(schema *yaml.Node, campo string) {
+			validationErrors = validarEnums(schema, &validationErrors, p, r, campo, verificarEnumDocumentado)
+		})
+
+	case "enum-changes":
+		validationErrors = validarMudancasDeEnum(rootNode, anterior, &validationErrors, r)
+
+	case "array-objects-max-items":
+		validarSchemas(func(schema *yaml.Node, campo string) {
+			validationErrors = validarArrayMaxItems(schema, &validationErrors, p, r, campo)
+		})
 	}
 
-	fmt.Println("✅ OpenAPI válido com regras aplicadas:", filePath)
-	return nil
+	return validationErrors
 }
 
-func validarPaths(paths map[string]map[string]*index.Reference, validationErrors *[]error, severity string, ruleData map[string]interface{}, campo string) []error {
+func validarPaths(paths map[string]map[string]*index.Reference, validationErrors *[]error, r regra, campo string) []error {
 	for _, methods := range paths {
 		for _, ref := range methods {
-			request := ref.Node.Content
-			for i := 0; i < len(request); i += 2 {
-				if request[i].Kind == yaml.ScalarNode && request[i].Value == campo {
-
-					valorCampo, _ := yaml.Marshal(request[i+1])
-
-					if len(strings.TrimSpace(string(valorCampo))) == 0 {
-						*validationErrors = append(*validationErrors, fmt.Errorf("[%s] %s", severity, ruleData["description"]))
-					}
-				}
+			if valorCampo := valorDoMapa(ref.Node, campo); valorCampo != nil && vazio(valorCampo) {
+				*validationErrors = append(*validationErrors, r.finding(""))
 			}
 		}
 	}
 	return *validationErrors
 }
 
-func validarInfo(aquivoNode *yaml.Node, validationErrors *[]error, severity string, ruleData map[string]interface{}, campo string) []error {
-	var infoVal string
-
-	for i := 0; i < len(aquivoNode.Content); i += 2 {
-		nodeArquivo := aquivoNode.Content[i]
-		if nodeArquivo.Kind == yaml.ScalarNode && nodeArquivo.Value == "info" {
-			infoNode := aquivoNode.Content[i+1]
-			for j := 0; j < len(infoNode.Content); j += 2 {
-				nodeInfo := infoNode.Content[j]
-				if nodeInfo.Kind == yaml.ScalarNode && nodeInfo.Value == campo {
-					infoVal = infoNode.Content[j+1].Value
-				}
-			}
-		}
-	}
-
-	if strings.TrimSpace(infoVal) == "" {
-		*validationErrors = append(*validationErrors, fmt.Errorf("[%s] %s", severity, ruleData["description"]))
+func validarInfo(aquivoNode *yaml.Node, validationErrors *[]error, r regra, campo string) []error {
+	if vazio(valorDoMapa(valorDoMapa(aquivoNode, "info"), campo)) {
+		*validationErrors = append(*validationErrors, r.finding(""))
 	}
 
 	return *validationErrors
 }
 
-func validarArrayMaxItems(schema []*yaml.Node, validationErrors *[]error, severity string, ruleData map[string]interface{}, campo string) []error {
-	for i := 0; i < len(schema); i += 2 {
-		if schema[i+1].Value == "array" {
-			var hasMaxItems = false
-			for j := 0; j < len(schema); j += 2 {
-				if schema[j].Value == "maxItems" {
-					hasMaxItems = true
-					break
-				}
-			}
-			if !hasMaxItems {
-				*validationErrors = append(*validationErrors, fmt.Errorf("[%s] campo: %s - %s", severity, campo, ruleData["description"]))
-			}
-		}
-		if schema[i].Value == "properties" {
-			subSchema := schema[i+1].Content
-			for j := 0; j < len(subSchema); j += 2 {
-				*validationErrors = validarArrayMaxItems(subSchema[j+1].Content, validationErrors, severity, ruleData, campo+"/"+subSchema[j].Value)
-			}
-		}
-		if schema[i].Value == "items" {
-			*validationErrors = validarArrayMaxItems(schema[i+1].Content, validationErrors, severity, ruleData, campo+"/"+schema[i+1].Content[0].Value)
+// Função para visitar os sub-schemas em "properties" e "items", seguindo $refs, aliases e merge keys
+func visitarFilhos(schema *yaml.Node, p *percurso, campo string, visita func(sub *yaml.Node, campo string)) {
+	for _, par := range paresDoMapa(schema) {
+		switch par.chave.Value {
+		case "properties":
+			campoProperties := p.campoDoPar(schema, par, campo)
+			for _, propriedade := range paresDoMapa(par.valor) {
+				p.visitar(propriedade.valor, p.campoDoPar(par.valor, propriedade, campoProperties), visita)
+			}
+		case "items":
+			p.visitar(par.valor, p.campoDoPar(schema, par, campo), visita)
 		}
 	}
-	return *validationErrors
 }
 
-func validarPropriedadeString(schema []*yaml.Node, validationErrors *[]error, severity string, ruleData map[string]interface{}, propriedade string, campo string) []error {
-	for i := 0; i < len(schema); i += 2 {
-		if schema[i+1].Value == "string" {
-			var hasPropriedade = false
-			var isEnum = false
-			for j := 0; j < len(schema); j += 2 {
-				if schema[j].Value == "enum" {
-					isEnum = true
-					break
-				} else {
-					if schema[j].Value == propriedade {
-						hasPropriedade = true
-						break
-					}
-				}
-			}
-			if !hasPropriedade && !isEnum {
-				*validationErrors = append(*validationErrors, fmt.Errorf("[%s] campo: %s - %s", severity, campo, ruleData["description"]))
-			}
-		}
-		if schema[i].Value == "properties" {
-			subSchema := schema[i+1].Content
-			for j := 0; j < len(subSchema); j += 2 {
-				*validationErrors = validarPropriedadeString(subSchema[j+1].Content, validationErrors, severity, ruleData, propriedade, campo+"/"+subSchema[j].Value)
-			}
-		}
-		if schema[i].Value == "items" {
-			*validationErrors = validarPropriedadeString(schema[i+1].Content, validationErrors, severity, ruleData, propriedade, campo+"/"+schema[i+1].Content[0].Value)
+// Função para verificar se o schema é do tipo informado, aceitando também a lista de tipos do OpenAPI 3.1
+// (ex.: type: ["string", "null"])
+func temTipo(schema *yaml.Node, tipo string) bool {
+	node := valorDoMapa(schema, "type")
+	if valor, ok := texto(node); ok {
+		return valor == tipo
+	}
+	for _, item := range itensDaLista(node) {
+		if item.Value == tipo {
+			return true
 		}
 	}
+	return false
+}
+
+// Função para verificar se o schema tem valores fixos: "enum" ou o "const" do OpenAPI 3.1
+func enumerado(schema *yaml.Node) bool {
+	return temChave(schema, "enum") || temChave(schema, "const")
+}
+
+func validarArrayMaxItems(schema *yaml.Node, validationErrors *[]error, p *percurso, r regra, campo string) []error {
+	if temTipo(schema, "array") && !temChave(schema, "maxItems") {
+		*validationErrors = append(*validationErrors, r.finding(campo))
+	}
+	visitarFilhos(schema, p, campo, func(sub *yaml.Node, campo string) {
+		*validationErrors = validarArrayMaxItems(sub, validationErrors, p, r, campo)
+	})
 	return *validationErrors
 }
 
-func validarPropriedadeEnum(schema []*yaml.Node, validationErrors *[]error, severity string, ruleData map[string]interface{}, propriedade string, campo string) []error {
-	for i := 0; i < len(schema); i += 2 {
-		if schema[i].Value == "enum" {
-			var hasPropriedade = false
-			for j := 0; j < len(schema); j += 2 {
-				if schema[j].Value == propriedade {
-					hasPropriedade = true
-					break
-				}
-			}
-			if hasPropriedade {
-				*validationErrors = append(*validationErrors, fmt.Errorf("[%s] campo: %s - %s", severity, campo, ruleData["description"]))
-			}
-		}
-		if schema[i].Value == "properties" {
-			subSchema := schema[i+1].Content
-			for j := 0; j < len(subSchema); j += 2 {
-				*validationErrors = validarPropriedadeEnum(subSchema[j+1].Content, validationErrors, severity, ruleData, propriedade, campo+"/"+subSchema[j].Value)
-			}
-		}
-		if schema[i].Value == "items" {
-			*validationErrors = validarPropriedadeEnum(schema[i+1].Content, validationErrors, severity, ruleData, propriedade, campo+"/"+schema[i+1].Content[0].Value)
-		}
+func validarPropriedadeString(schema *yaml.Node, validationErrors *[]error, p *percurso, r regra, propriedade string, campo string) []error {
+	if temTipo(schema, "string") && !enumerado(schema) && !temChave(schema, propriedade) {
+		*validationErrors = append(*validationErrors, r.finding(campo))
 	}
+	visitarFilhos(schema, p, campo, func(sub *yaml.Node, campo string) {
+		*validationErrors = validarPropriedadeString(sub, validationErrors, p, r, propriedade, campo)
+	})
 	return *validationErrors
 }
 
+func validarPropriedadeEnum(schema *yaml.Node, validationErrors *[]error, p *percurso, r regra, propriedade string, campo string) []error {
+	if temChave(schema, "enum") && temChave(schema, propriedade) {
+		*validationErrors = append(*validationErrors, r.finding(campo))
+	}
+	visitarFilhos(schema, p, campo, func(sub *yaml.Node, campo string) {
+		*validationErrors = validarPropriedadeEnum(sub, validationErrors, p, r, propriedade, campo)
+	})
+	return *validationErrors
+}
 
-func validarPattern(schema []*yaml.Node, validationErrors *[]error, severity string, ruleData map[string]interface{}, pattern string, campo string) []error {
-	for i := 0; i < len(schema); i += 2 {
-		if schema[i].Value == "pattern" {
-			re := regexp.MustCompile(pattern)
+func validarPattern(schema *yaml.Node, validationErrors *[]error, p *percurso, r regra, pattern string, campo string) []error {
+	if valor, ok := texto(valorDoMapa(schema, "pattern")); ok {
+		re := regexp.MustCompile(pattern)
 
-			if re.MatchString(schema[i+1].Value) {
-				*validationErrors = append(*validationErrors, fmt.Errorf("[%s] campo: %s - %s", severity, campo, ruleData["description"]))
-			}
-		}
-		if schema[i].Value == "properties" {
-			subSchema := schema[i+1].Content
-			for j := 0; j < len(subSchema); j += 2 {
-				*validationErrors = validarPattern(subSchema[j+1].Content, validationErrors, severity, ruleData, pattern, campo+"/"+subSchema[j].Value)
-			}
-		}
-		if schema[i].Value == "items" {
-			*validationErrors = validarPattern(schema[i+1].Content, validationErrors, severity, ruleData, pattern, campo+"/"+schema[i+1].Content[0].Value)
+		if re.MatchString(valor) {
+			*validationErrors = append(*validationErrors, r.finding(campo))
 		}
 	}
+	visitarFilhos(schema, p, campo, func(sub *yaml.Node, campo string) {
+		*validationErrors = validarPattern(sub, validationErrors, p, r, pattern, campo)
+	})
 	return *validationErrors
 }
 
-func validarPatternString(schema []*yaml.Node, validationErrors *[]error, severity string, ruleData map[string]interface{}, campo string) []error {
-	var example = ""
-	for i := 0; i < len(schema); i += 2 {
-		if schema[i].Value == "properties" {
-			subSchema := schema[i+1].Content
-			for j := 0; j < len(subSchema); j += 2 {
-				*validationErrors = validarPatternString(subSchema[j+1].Content, validationErrors, severity, ruleData, campo+"/"+subSchema[j].Value)
-			}
-		}
-		if schema[i].Value == "items" {
-			*validationErrors = validarPatternString(schema[i+1].Content, validationErrors, severity, ruleData, campo+"/"+schema[i+1].Content[0].Value)
-		}
-		if example == "" {
-			if schema[i].Value == "enum" {
-				break
-			}
-			if schema[i+1].Value == "string" {
-				for j := 0; j < len(schema); j += 2 {
-					if schema[j].Value == "pattern" {
-						example = schema[j+1].Value
-					}
-				}
-			}
-		}
+func validarPatternString(schema *yaml.Node, validationErrors *[]error, p *percurso, r regra, campo string) []error {
+	visitarFilhos(schema, p, campo, func(sub *yaml.Node, campo string) {
+		*validationErrors = validarPatternString(sub, validationErrors, p, r, campo)
+	})
+	if !temTipo(schema, "string") || enumerado(schema) {
+		return *validationErrors
 	}
+	example, _ := texto(valorDoMapa(schema, "pattern"))
 	if example != "" && strings.TrimSpace(example) != example {
-		*validationErrors = append(*validationErrors, fmt.Errorf("[%s] campo: %s - %s", severity, campo, ruleData["description"]))
+		*validationErrors = append(*validationErrors, r.finding(campo))
 	}
 	return *validationErrors
 }
 
-func validarObjeto(schema []*yaml.Node, validationErrors *[]error, severity string, ruleData map[string]interface{}, campo string) []error {
-	for i := 0; i < len(schema); i += 2 {
-		if schema[i].Value == "properties" {
-			subSchema := schema[i+1].Content
-			for j := 0; j < len(subSchema); j += 2 {
-				*validationErrors = validarObjeto(subSchema[j+1].Content, validationErrors, severity, ruleData, campo+"/"+subSchema[j].Value)
-			}
-		}
-		if schema[i].Value == "items" {
-			*validationErrors = validarObjeto(schema[i+1].Content, validationErrors, severity, ruleData, campo+"/"+schema[i+1].Content[0].Value)
-		}
-		if schema[i+1].Value == "object" {
-			var requiredFields []string
-			var properiesFields []string
-			for j := 0; j < len(schema); j += 2 {
-				// campo+"/"+schema[j+1].Content[0].Value
-				if schema[j].Value == "required" {
-					required := schema[j+1].Content
-					for k := 0; k < len(required); k++ {
-
-						requiredFields = append(requiredFields, required[k].Value)
-					}
-				}
-				if schema[j].Value == "properties" {
-					properties := schema[j+1].Content
-					for k := 0; k < len(properties); k += 2 {
-
-						properiesFields = append(properiesFields, properties[k].Value)
-					}
-				}
-			}
-			set := make(map[string]bool)
-			for _, item := range properiesFields {
-				set[item] = true
-			}
-			for _, item := range requiredFields {
-				if !set[item] {
-					*validationErrors = append(*validationErrors, fmt.Errorf("[%s] campo: %s - %s", severity, campo+"/"+item, ruleData["description"]))
-				}
-			}
+func validarObjeto(schema *yaml.Node, validationErrors *[]error, p *percurso, r regra, campo string) []error {
+	visitarFilhos(schema, p, campo, func(sub *yaml.Node, campo string) {
+		*validationErrors = validarObjeto(sub, validationErrors, p, r, campo)
+	})
+	if !temTipo(schema, "object") {
+		return *validationErrors
+	}
+	properties := valorDoMapa(schema, "properties")
+	for _, required := range itensDaLista(valorDoMapa(schema, "required")) {
+		if !temChave(properties, required.Value) {
+			*validationErrors = append(*validationErrors, r.finding(campo+"/"+required.Value))
 		}
 	}
 	return *validationErrors
 }
 
-// Função para resolver referências OpenAPI e salvar o arquivo resolvido
-func resolveOpenAPI(inputFile, outputFile string) error {
+// Função para resolver referências OpenAPI e salvar o arquivo resolvido; a formatação escolhe o formato e a
+// indentação da saída (por padrão, a extensão do arquivo de saída e a indentação da entrada); as opções indicam
+// onde encontrar os arquivos e documentos remotos referenciados
+func resolveOpenAPI(inputFile, outputFile string, formatacao opcoesSaida, opcoes opcoesReferencias) error {
 	// Ler o arquivo e converter para UTF-8
 	data, err := readFile(inputFile)
 	if err != nil {
 		return err
 	}
 
-	// Criar um nó YAML a partir do arquivo
-	var rootNode yaml.Node
-	if err := yaml.Unmarshal(data, &rootNode); err != nil {
-		return fmt.Errorf("erro ao fazer unmarshal do YAML: %v", err)
+	// Criar um nó YAML a partir do arquivo (YAML ou JSON)
+	rootNode, formatoEntrada, err := lerDocumento(data)
+	if err != nil {
+		return err
 	}
 
-	// Criar uma configuração para o indexador
-	indexConfig := index.CreateClosedAPIIndexConfig()
-
-	// Criar um novo rolodex para gerenciar referências
-	rolodex := index.NewRolodex(indexConfig)
-
-	// Definir o root node do rolodex
-	rolodex.SetRootNode(&rootNode)
+	saida, err := escolherFormatoSaida(formatacao.formato, outputFile, formatoEntrada)
+	if err != nil {
+		return err
+	}
+	indentacao, err := escolherIndentacao(formatacao.indentacao, data)
+	if err != nil {
+		return err
+	}
 
-	// Indexar as referências do OpenAPI
-	if err := rolodex.IndexTheRolodex(); err != nil {
-		return fmt.Errorf("erro ao indexar as referências: %v", err)
+	// Criar um rolodex com acesso aos arquivos referenciados e indexar as referências do OpenAPI
+	rolodex, err := novoRolodex(rootNode, inputFile, opcoes)
+	if err != nil {
+		return err
+	}
+	if referencias := errosDeReferencia(rolodex); len(referencias) > 0 {
+		localizarFindings(rootNode, referencias)
+		for _, err := range referencias {
+			fmt.Println("❌ Erro de referência:", err)
+		}
+		return fmt.Errorf("erro ao indexar as referências de %s", inputFile)
 	}
 
-	// Resolver todas as referências
+	// Resolver todas as referências; $refs que não puderam ser substituídos falham a resolução, em vez de gerar
+	// um documento incompleto, e as referências circulares seguem a política das opções
 	rolodex.Resolve()
+	if problemas := errosDeResolucao(rolodex, opcoes.circulares); len(problemas) > 0 {
+		localizarFindings(rootNode, problemas)
+		falhou := false
+		for _, err := range problemas {
+			if !isErro(err) {
+				fmt.Println("⚠️ Aviso de referência:", err)
+				continue
+			}
+			falhou = true
+			fmt.Println("❌ Erro de referência:", err)
+		}
+		if falhou {
+			return fmt.Errorf("erro ao resolver as referências de %s", inputFile)
+		}
+	}
 
-	// Criar um YAML resolvido a partir do rolodex atualizado
-	resolvedYAML, err := yaml.Marshal(&rootNode)
+	// Criar o documento resolvido a partir do rolodex atualizado
+	resolved, err := codificarDocumento(rootNode, saida, indentacao)
 	if err != nil {
-		return fmt.Errorf("erro ao converter para YAML: %v", err)
+		return err
 	}
 
-	// Salvar o YAML resolvido em um novo arquivo
-	if err := ioutil.WriteFile(outputFile, resolvedYAML, 0644); err != nil {
+	// Salvar o documento resolvido em um novo arquivo
+	if err := ioutil.WriteFile(outputFile, resolved, 0644); err != nil {
 		return fmt.Errorf("erro ao salvar arquivo resolvido: %v", err)
 	}
 
@@ -564,8 +676,24 @@ func resolveOpenAPI(inputFile, outputFile string) error {
 }
 
 func main() {
+	if len(os.Args) > 1 {
+		if comando, ok := comandos[os.Args[1]]; ok {
+			if err := comando(os.Args[2:]); err != nil {
+				fmt.Println("❌", err)
+				os.Exit(1)
+			}
+			return
+		}
+	}
+
 	if len(os.Args) < 4 {
-		fmt.Println("Uso: go run main.go oldSwagger.yaml swagger.yaml pb33f_rules.yaml")
+		fmt.Println("Uso: go run ./rules oldSwagger.yaml swagger.yaml pb33f_rules.yaml")
+		fmt.Println("     go run ./rules validate [--circular-refs allow|warn|error] [--previous anterior.yaml] [--base-dir dir] [--ref-map refs.yaml] swagger.yaml pb33f_rules.yaml")
+		fmt.Println("     go run ./rules resolve [--circular-refs allow|warn|error] [--output-format json|yaml] [--indent n] [--base-dir dir] [--ref-map refs.yaml] entrada.yaml saida.json")
+		fmt.Println("     go run ./rules bundle [--output-format json|yaml] [--indent n] [--base-dir dir] [--ref-map refs.yaml] entrada.yaml saida.yaml")
+		fmt.Println("     go run ./rules vendor-refs --ref-map refs.yaml [--base-dir dir] swagger.yaml...")
+		fmt.Println("     go run ./rules prune [--dry-run] [--output-format json|yaml] [--indent n] [--base-dir dir] [--ref-map refs.yaml] entrada.yaml [saida.yaml]")
+		fmt.Println("     go run ./rules convert [--output-format json|yaml] [--indent n] swagger2.yaml openapi3.yaml")
 		return
 	}
 
@@ -574,23 +702,23 @@ func main() {
 	rulesFile := os.Args[3]
 
 	// Validar arquivos com regras personalizadas antes de resolver
-	if err := validateOpenAPIWithRules(oldFile, rulesFile); err != nil {
+	if err := validateOpenAPIWithRules(oldFile, rulesFile, opcoesReferencias{}, ""); err != nil {
 		fmt.Println("❌ OpenAPI inválido:", oldFile)
 		os.Exit(1)
 	}
 
-	if err := validateOpenAPIWithRules(newFile, rulesFile); err != nil {
+	if err := validateOpenAPIWithRules(newFile, rulesFile, opcoesReferencias{}, oldFile); err != nil {
 		fmt.Println("❌ OpenAPI inválido:", newFile)
 		os.Exit(1)
 	}
 
 	// Resolver e salvar os arquivos
-	if err := resolveOpenAPI(oldFile, "oldSwaggerResolve.yaml"); err != nil {
+	if err := resolveOpenAPI(oldFile, "oldSwaggerResolve.yaml", opcoesSaida{}, opcoesReferencias{}); err != nil {
 		fmt.Println("❌ Erro ao processar oldSwagger.yaml:", err)
 		os.Exit(1)
 	}
 
-	if err := resolveOpenAPI(newFile, "swaggerResolve.yaml"); err != nil {
+	if err := resolveOpenAPI(newFile, "swaggerResolve.yaml", opcoesSaida{}, opcoesReferencias{}); err != nil {
 		fmt.Println("❌ Erro ao processar swagger.yaml:", err)
 		os.Exit(1)
 	}
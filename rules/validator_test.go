@@ -0,0 +1,147 @@
+package main
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// Os documentos malformados devem gerar findings, nunca um panic nem uma falha interna de regra
+func TestValidarDocumentoMalformado(t *testing.T) {
+	rules, err := loadRules("pb33f_rules.yaml")
+	if err != nil {
+		t.Fatalf("erro ao carregar as regras: %v", err)
+	}
+
+	casos := []struct {
+		nome     string
+		conteudo string
+		parse    bool // espera um finding de parse; senão, ao menos um finding localizado
+	}{
+		{"documento vazio", "", true},
+		{"apenas comentários", "# nada aqui\n", true},
+		{"raiz sequence", "- openapi: 3.0.0\n- info: {}\n", true},
+		{"raiz escalar", "openapi\n", true},
+		{"YAML inválido", "openapi: 3.0.0\ninfo: [\n", true},
+		{"JSON inválido", `{"openapi": "3.0.0", "info": {`, true},
+		{"alias indefinido", "openapi: 3.0.0\ninfo: *nada\n", true},
+		{"alias em ciclo", "openapi: 3.0.0\ninfo: &a\n  title: *a\n", true},
+		{"mapping em estilo flow", "{openapi: 3.0.0, info: {title: t, version: '1'}, paths: {/a: {get: {responses: {'200': {description: ok}}}}}}\n", false},
+		{"items null", `
+openapi: 3.0.0
+info: {title: t, version: "1"}
+paths: {}
+components:
+  schemas:
+    Lista:
+      type: array
+      items: null
+`, false},
+		{"paths e info com tipo errado", "openapi: 3.0.0\ninfo: texto\npaths: [a, b]\n", false},
+		{"paths com valores nulos", "openapi: 3.0.0\ninfo: {title: t, version: '1'}\npaths:\n  /a:\n  /b:\n    get:\n", false},
+		{"merge key com alias", `
+openapi: 3.0.0
+info: {title: t, version: "1"}
+paths: {}
+components:
+  schemas:
+    Base: &base
+      type: object
+      properties: {id: {type: string}}
+    Derivado:
+      <<: *base
+      required: [id]
+`, false},
+	}
+
+	for _, c := range casos {
+		t.Run(c.nome, func(t *testing.T) {
+			defer func() {
+				if recuperado := recover(); recuperado != nil {
+					t.Fatalf("panic ao validar: %v", recuperado)
+				}
+			}()
+			arquivo := filepath.Join(t.TempDir(), "openapi.yaml")
+			validationErrors, err := validarDocumento([]byte(c.conteudo), arquivo, rules, nil, opcoesReferencias{})
+			if err != nil {
+				t.Fatalf("erro ao validar: %v", err)
+			}
+
+			var parse, localizado bool
+			for _, e := range validationErrors {
+				var f *finding
+				if !errors.As(e, &f) {
+					continue
+				}
+				if strings.HasPrefix(f.descricao, "falha interna") {
+					t.Errorf("a regra %s falhou: %s", f.regra, f.descricao)
+				}
+				parse = parse || f.regra == "parse"
+				localizado = localizado || f.linha > 0
+			}
+			if c.parse && !parse {
+				t.Errorf("esperado um finding de parse, encontrado %v", validationErrors)
+			}
+			if !c.parse && !localizado {
+				t.Errorf("esperado ao menos um finding localizado, encontrado %v", validationErrors)
+			}
+		})
+	}
+}
+
+// Os findings de schemas em arquivos referenciados apontam para o schema no próprio arquivo, e não para o $ref
+func TestFindingsEmArquivoReferenciado(t *testing.T) {
+	rules, err := loadRules("pb33f_rules.yaml")
+	if err != nil {
+		t.Fatalf("erro ao carregar as regras: %v", err)
+	}
+	dir := t.TempDir()
+	if err := os.MkdirAll(filepath.Join(dir, "schemas"), 0755); err != nil {
+		t.Fatal(err)
+	}
+	api := `openapi: 3.0.0
+info: {title: t, version: "1"}
+paths:
+  /p:
+    get:
+      responses:
+        "200":
+          description: ok
+          content:
+            application/json:
+              schema:
+                $ref: ./schemas/Payment.yaml
+`
+	payment := `type: object
+properties:
+  id:
+    type: integer
+  nome:
+    type: string
+`
+	if err := os.WriteFile(filepath.Join(dir, "schemas", "Payment.yaml"), []byte(payment), 0644); err != nil {
+		t.Fatal(err)
+	}
+	arquivo := filepath.Join(dir, "api.yaml")
+	validationErrors, err := validarDocumento([]byte(api), arquivo, rules, nil, opcoesReferencias{})
+	if err != nil {
+		t.Fatalf("erro ao validar: %v", err)
+	}
+
+	var encontrado *finding
+	for _, e := range validationErrors {
+		var f *finding
+		if errors.As(e, &f) && f.regra == "string-should-has-maxLength" {
+			encontrado = f
+		}
+	}
+	if encontrado == nil {
+		t.Fatalf("esperado um finding de string-should-has-maxLength, encontrado %v", validationErrors)
+	}
+	if encontrado.campo != "schemas/Payment.yaml#/properties/nome" || encontrado.linha != 6 || encontrado.coluna != 5 {
+		t.Errorf("finding em %s (linha %d, coluna %d), esperado schemas/Payment.yaml#/properties/nome (linha 6, coluna 5)",
+			encontrado.campo, encontrado.linha, encontrado.coluna)
+	}
+}
@@ -0,0 +1,99 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// versaoSpec identifica a versão da especificação do documento, com os mesmos nomes usados em "formats" nas regras
+type versaoSpec string
+
+const (
+	versaoDesconhecida versaoSpec = ""
+	versaoOAS2         versaoSpec = "oas2"
+	versaoOAS3_0       versaoSpec = "oas3_0"
+	versaoOAS3_1       versaoSpec = "oas3_1"
+)
+
+// formatos aceitos no campo "formats" das regras; oas3 engloba 3.0 e 3.1
+var formatosValidos = map[string][]versaoSpec{
+	"oas2":   {versaoOAS2},
+	"oas3":   {versaoOAS3_0, versaoOAS3_1},
+	"oas3_0": {versaoOAS3_0},
+	"oas3_1": {versaoOAS3_1},
+}
+
+// Função para detectar a versão da especificação pelos campos "openapi" ou "swagger"
+func detectarVersao(doc *yaml.Node) (versaoSpec, string) {
+	if valor, ok := texto(valorDoMapa(doc, "openapi")); ok {
+		valor = strings.TrimSpace(valor)
+		switch {
+		case strings.HasPrefix(valor, "3.0"):
+			return versaoOAS3_0, valor
+		case strings.HasPrefix(valor, "3.1"):
+			return versaoOAS3_1, valor
+		}
+		return versaoDesconhecida, valor
+	}
+	if valor, ok := texto(valorDoMapa(doc, "swagger")); ok {
+		valor = strings.TrimSpace(valor)
+		if valor == "2.0" || valor == "2" {
+			return versaoOAS2, valor
+		}
+		return versaoDesconhecida, valor
+	}
+	return versaoDesconhecida, ""
+}
+
+// Função para validar a versão da especificação, reportando documentos sem versão ou com versão não suportada
+func validarVersao(doc *yaml.Node) []error {
+	versao, valor := detectarVersao(doc)
+	if versao != versaoDesconhecida {
+		return nil
+	}
+	if valor == "" {
+		return []error{&finding{
+			regra:      "spec-version",
+			severidade: "error",
+			descricao:  "O documento deve declarar a versão da especificação no campo `openapi` ou `swagger`",
+		}}
+	}
+	return []error{&finding{
+		regra:      "spec-version",
+		severidade: "warn",
+		descricao:  fmt.Sprintf("Versão da especificação %q não suportada; apenas as regras sem `formats` foram aplicadas", valor),
+	}}
+}
+
+// Função para converter a lista "formats" de uma regra nas versões em que ela se aplica
+func parseFormatos(valor interface{}) ([]versaoSpec, error) {
+	lista, ok := valor.([]interface{})
+	if !ok {
+		return nil, fmt.Errorf("formats deve ser uma lista (oas2, oas3, oas3_0, oas3_1)")
+	}
+	var versoes []versaoSpec
+	for _, item := range lista {
+		nome := fmt.Sprint(item)
+		aceitas, ok := formatosValidos[nome]
+		if !ok {
+			return nil, fmt.Errorf("formato inválido %q, use oas2, oas3, oas3_0 ou oas3_1", nome)
+		}
+		versoes = append(versoes, aceitas...)
+	}
+	return versoes, nil
+}
+
+// Função para verificar se a regra se aplica à versão do documento; regras sem formats se aplicam a todas
+func (r regra) aplicaA(versao versaoSpec) bool {
+	if len(r.formatos) == 0 {
+		return true
+	}
+	for _, formato := range r.formatos {
+		if formato == versao {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,324 @@
+package main
+
+import (
+	"strconv"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// Funções auxiliares para acessar nós YAML sem aritmética de índices. Todas aceitam nós nulos,
+// resolvem aliases e merge keys (<<) e nunca causam panic com documentos vazios ou malformados.
+
+// parYAML representa um par chave/valor de um mapping; origem é o mapping onde o par está escrito,
+// que é diferente do mapping consultado quando o par vem de uma merge key
+type parYAML struct {
+	chave  *yaml.Node
+	valor  *yaml.Node
+	origem *yaml.Node
+}
+
+// Função para seguir aliases (*ref) até o nó com o conteúdo real
+func resolverAlias(node *yaml.Node) *yaml.Node {
+	for i := 0; node != nil && node.Kind == yaml.AliasNode && i < 100; i++ {
+		node = node.Alias
+	}
+	if node != nil && node.Kind == yaml.AliasNode {
+		return nil
+	}
+	return node
+}
+
+// Função para obter o mapping raiz de um documento YAML, ou nil se o documento estiver vazio
+func documento(rootNode *yaml.Node) *yaml.Node {
+	node := resolverAlias(rootNode)
+	if node != nil && node.Kind == yaml.DocumentNode {
+		if len(node.Content) == 0 {
+			return nil
+		}
+		node = resolverAlias(node.Content[0])
+	}
+	if node == nil || node.Kind != yaml.MappingNode {
+		return nil
+	}
+	return node
+}
+
+// Função para listar os pares chave/valor de um mapping, incluindo os pares trazidos por merge keys
+func paresDoMapa(node *yaml.Node) []parYAML {
+	return coletarPares(node, make(map[*yaml.Node]bool))
+}
+
+func coletarPares(node *yaml.Node, vistos map[*yaml.Node]bool) []parYAML {
+	node = resolverAlias(node)
+	if node == nil || node.Kind != yaml.MappingNode || vistos[node] {
+		return nil
+	}
+	vistos[node] = true
+
+	pares := make([]parYAML, 0, len(node.Content)/2)
+	var mesclados []*yaml.Node
+	for i := 0; i+1 < len(node.Content); i += 2 {
+		chave := resolverAlias(node.Content[i])
+		valor := resolverAlias(node.Content[i+1])
+		if chave == nil || valor == nil {
+			continue
+		}
+		if chave.Kind == yaml.ScalarNode && chave.ShortTag() == "!!merge" {
+			if valor.Kind == yaml.SequenceNode {
+				mesclados = append(mesclados, valor.Content...)
+			} else {
+				mesclados = append(mesclados, valor)
+			}
+			continue
+		}
+		pares = append(pares, parYAML{chave, valor, node})
+	}
+
+	// as chaves escritas no mapping têm precedência sobre as mescladas, e a primeira merge key sobre as seguintes
+	presentes := make(map[string]bool, len(pares))
+	for _, par := range pares {
+		presentes[par.chave.Value] = true
+	}
+	for _, mesclado := range mesclados {
+		for _, par := range coletarPares(mesclado, vistos) {
+			if !presentes[par.chave.Value] {
+				presentes[par.chave.Value] = true
+				pares = append(pares, par)
+			}
+		}
+	}
+	return pares
+}
+
+// Função para buscar o valor de uma chave em um nó do tipo mapping
+func valorDoMapa(node *yaml.Node, chave string) *yaml.Node {
+	for _, par := range paresDoMapa(node) {
+		if par.chave.Kind == yaml.ScalarNode && par.chave.Value == chave {
+			return par.valor
+		}
+	}
+	return nil
+}
+
+// Função para verificar se um mapping possui a chave informada
+func temChave(node *yaml.Node, chave string) bool {
+	return valorDoMapa(node, chave) != nil
+}
+
+// Função para listar os itens de uma sequence
+func itensDaLista(node *yaml.Node) []*yaml.Node {
+	node = resolverAlias(node)
+	if node == nil || node.Kind != yaml.SequenceNode {
+		return nil
+	}
+	itens := make([]*yaml.Node, 0, len(node.Content))
+	for _, item := range node.Content {
+		if item = resolverAlias(item); item != nil {
+			itens = append(itens, item)
+		}
+	}
+	return itens
+}
+
+// Função para ler um escalar como texto
+func texto(node *yaml.Node) (string, bool) {
+	node = resolverAlias(node)
+	if node == nil || node.Kind != yaml.ScalarNode {
+		return "", false
+	}
+	return node.Value, true
+}
+
+// Função para ler um escalar como inteiro
+func inteiro(node *yaml.Node) (int, bool) {
+	valor, ok := texto(node)
+	if !ok {
+		return 0, false
+	}
+	numero, err := strconv.Atoi(strings.TrimSpace(valor))
+	if err != nil {
+		return 0, false
+	}
+	return numero, true
+}
+
+// Função para ler um escalar como booleano
+func booleano(node *yaml.Node) (bool, bool) {
+	var valor bool
+	node = resolverAlias(node)
+	if node == nil || node.Kind != yaml.ScalarNode || node.Decode(&valor) != nil {
+		return false, false
+	}
+	return valor, true
+}
+
+// Função para verificar se um nó é vazio: ausente, escalar em branco/nulo, mapping ou sequence sem itens
+func vazio(node *yaml.Node) bool {
+	node = resolverAlias(node)
+	if node == nil {
+		return true
+	}
+	switch node.Kind {
+	case yaml.ScalarNode:
+		return node.Tag == "!!null" || strings.TrimSpace(node.Value) == ""
+	case yaml.MappingNode, yaml.SequenceNode:
+		return len(node.Content) == 0
+	}
+	return false
+}
+
+// Função para encontrar um alias que aponta para um nó que o contém, ou nil se não houver
+func aliasRecursivo(rootNode *yaml.Node) *yaml.Node {
+	emAndamento := make(map[*yaml.Node]bool)
+	concluidos := make(map[*yaml.Node]bool)
+	var buscar func(node *yaml.Node) *yaml.Node
+	buscar = func(node *yaml.Node) *yaml.Node {
+		if node == nil || concluidos[node] {
+			return nil
+		}
+		if node.Kind == yaml.AliasNode {
+			if emAndamento[node.Alias] {
+				return node
+			}
+			return buscar(node.Alias)
+		}
+		emAndamento[node] = true
+		for _, filho := range node.Content {
+			if alias := buscar(filho); alias != nil {
+				return alias
+			}
+		}
+		delete(emAndamento, node)
+		concluidos[node] = true
+		return nil
+	}
+	return buscar(rootNode)
+}
+
+// Função para mapear cada nó com âncora (&nome) para o JSON pointer da sua definição no documento
+func mapearAncoras(rootNode *yaml.Node) map[*yaml.Node]string {
+	ancoras := make(map[*yaml.Node]string)
+	var mapear func(node *yaml.Node, ponteiro string)
+	mapear = func(node *yaml.Node, ponteiro string) {
+		if node == nil || node.Kind == yaml.AliasNode {
+			return
+		}
+		if node.Anchor != "" {
+			ancoras[node] = ponteiro
+		}
+		switch node.Kind {
+		case yaml.DocumentNode:
+			for _, filho := range node.Content {
+				mapear(filho, ponteiro)
+			}
+		case yaml.MappingNode:
+			for i := 0; i+1 < len(node.Content); i += 2 {
+				mapear(node.Content[i+1], ponteiro+"/"+escaparPonteiro(node.Content[i].Value))
+			}
+		case yaml.SequenceNode:
+			for i, item := range node.Content {
+				mapear(item, ponteiro+"/"+strconv.Itoa(i))
+			}
+		}
+	}
+	mapear(rootNode, "#")
+	return ancoras
+}
+
+// Função para buscar o nó indicado por um JSON pointer (#/a/b); quando o caminho não existe por completo,
+// retorna o nó mais profundo encontrado
+func buscarPonteiro(rootNode *yaml.Node, ponteiro string) *yaml.Node {
+	node, _ := percorrerPonteiro(rootNode, ponteiro)
+	return node
+}
+
+// Função para buscar o nó indicado por um JSON pointer, retornando nil quando o caminho não existe
+func nodeDoPonteiro(rootNode *yaml.Node, ponteiro string) *yaml.Node {
+	if node, completo := percorrerPonteiro(rootNode, ponteiro); completo {
+		return node
+	}
+	return nil
+}
+
+// Função para percorrer um JSON pointer, retornando o nó mais profundo encontrado e se o caminho existe por completo
+func percorrerPonteiro(rootNode *yaml.Node, ponteiro string) (*yaml.Node, bool) {
+	node := documento(rootNode)
+	if node == nil {
+		return nil, false
+	}
+	segmentos := strings.Split(strings.TrimPrefix(strings.TrimPrefix(ponteiro, "#"), "/"), "/")
+	for _, segmento := range segmentos {
+		if segmento == "" {
+			continue
+		}
+		segmento = strings.ReplaceAll(strings.ReplaceAll(segmento, "~1", "/"), "~0", "~")
+
+		var proximo *yaml.Node
+		switch node.Kind {
+		case yaml.MappingNode:
+			// busca direta no conteúdo para que segmentos "<<" apontem para a merge key escrita no documento
+			for i := 0; i+1 < len(node.Content); i += 2 {
+				if node.Content[i].Value == segmento {
+					proximo = resolverAlias(node.Content[i+1])
+					break
+				}
+			}
+			if proximo == nil {
+				// chaves trazidas por merge keys também fazem parte do mapping
+				proximo = valorDoMapa(node, segmento)
+			}
+		case yaml.SequenceNode:
+			if i, err := strconv.Atoi(segmento); err == nil && i >= 0 && i < len(node.Content) {
+				proximo = resolverAlias(node.Content[i])
+			}
+		}
+		if proximo == nil {
+			return node, false
+		}
+		node = proximo
+	}
+	return node, true
+}
+
+// Função para listar os valores de uma sequence de escalares
+func textosDaLista(node *yaml.Node) []string {
+	var textos []string
+	for _, item := range itensDaLista(node) {
+		if valor, ok := texto(item); ok {
+			textos = append(textos, valor)
+		}
+	}
+	return textos
+}
+
+// Função para criar um mapping vazio
+func novoMapa() *yaml.Node {
+	return &yaml.Node{Kind: yaml.MappingNode, Tag: "!!map"}
+}
+
+// Função para criar uma sequence com os itens informados
+func novaLista(itens ...*yaml.Node) *yaml.Node {
+	return &yaml.Node{Kind: yaml.SequenceNode, Tag: "!!seq", Content: itens}
+}
+
+// Função para criar um escalar do tipo string
+func novoTexto(valor string) *yaml.Node {
+	return &yaml.Node{Kind: yaml.ScalarNode, Tag: "!!str", Value: valor}
+}
+
+// Função para criar um escalar do tipo booleano
+func novoBooleano(valor bool) *yaml.Node {
+	return &yaml.Node{Kind: yaml.ScalarNode, Tag: "!!bool", Value: strconv.FormatBool(valor)}
+}
+
+// Função para definir o valor de uma chave no mapping, substituindo o valor existente ou adicionando a chave no final
+func definirValor(mapa *yaml.Node, chave string, valor *yaml.Node) {
+	for i := 0; i+1 < len(mapa.Content); i += 2 {
+		if mapa.Content[i].Value == chave {
+			mapa.Content[i+1] = valor
+			return
+		}
+	}
+	mapa.Content = append(mapa.Content, novoTexto(chave), valor)
+}
@@ -0,0 +1,149 @@
+package main
+
+import (
+	"reflect"
+	"testing"
+
+	"gopkg.in/yaml.v3"
+)
+
+// Função para interpretar um trecho YAML e retornar o mapping raiz
+func mapaDeTeste(t *testing.T, conteudo string) *yaml.Node {
+	t.Helper()
+	var rootNode yaml.Node
+	if err := yaml.Unmarshal([]byte(conteudo), &rootNode); err != nil {
+		t.Fatalf("YAML de teste inválido: %v", err)
+	}
+	return documento(&rootNode)
+}
+
+func TestValorDoMapa(t *testing.T) {
+	mapa := mapaDeTeste(t, "a: 1\nb: {c: texto}\nlista: [x]\n")
+
+	if valor, _ := texto(valorDoMapa(mapa, "a")); valor != "1" {
+		t.Errorf("valorDoMapa(a) = %q, esperado 1", valor)
+	}
+	if valor, _ := texto(valorDoMapa(valorDoMapa(mapa, "b"), "c")); valor != "texto" {
+		t.Errorf("valorDoMapa(b.c) = %q, esperado texto", valor)
+	}
+	if valorDoMapa(mapa, "ausente") != nil {
+		t.Error("valorDoMapa de uma chave ausente deve ser nil")
+	}
+	// nós nulos ou que não são mappings não causam panic
+	for _, node := range []*yaml.Node{nil, valorDoMapa(mapa, "a"), valorDoMapa(mapa, "lista"), {Kind: yaml.MappingNode, Content: []*yaml.Node{{Kind: yaml.ScalarNode, Value: "sem-valor"}}}} {
+		if valorDoMapa(node, "a") != nil {
+			t.Errorf("valorDoMapa(%v) deve ser nil", node)
+		}
+	}
+}
+
+func TestParesDoMapaComMergeKeys(t *testing.T) {
+	mapa := mapaDeTeste(t, `
+base: &base {a: 1, b: 2}
+outra: &outra {b: 3, c: 4}
+unico:
+  <<: *base
+  d: 5
+varios:
+  <<: [*base, *outra]
+  a: 0
+`)
+	chaves := func(node *yaml.Node) map[string]string {
+		valores := make(map[string]string)
+		for _, par := range paresDoMapa(node) {
+			valores[par.chave.Value] = par.valor.Value
+		}
+		return valores
+	}
+
+	if got, want := chaves(valorDoMapa(mapa, "unico")), map[string]string{"a": "1", "b": "2", "d": "5"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("pares com uma merge key = %v, esperado %v", got, want)
+	}
+	// as chaves escritas têm precedência, e a primeira merge key sobre as seguintes
+	if got, want := chaves(valorDoMapa(mapa, "varios")), map[string]string{"a": "0", "b": "2", "c": "4"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("pares com uma lista de merge keys = %v, esperado %v", got, want)
+	}
+	if paresDoMapa(nil) != nil {
+		t.Error("paresDoMapa(nil) deve ser nil")
+	}
+}
+
+func TestParesDoMapaComMergeCircular(t *testing.T) {
+	// um mapping que mescla a si mesmo só pode ser montado diretamente, pois o parser o rejeita
+	mapa := &yaml.Node{Kind: yaml.MappingNode}
+	alias := &yaml.Node{Kind: yaml.AliasNode, Alias: mapa}
+	mapa.Content = []*yaml.Node{
+		{Kind: yaml.ScalarNode, Tag: "!!merge", Value: "<<"}, alias,
+		{Kind: yaml.ScalarNode, Tag: "!!str", Value: "a"}, {Kind: yaml.ScalarNode, Tag: "!!str", Value: "1"},
+	}
+	if pares := paresDoMapa(mapa); len(pares) != 1 || pares[0].chave.Value != "a" {
+		t.Errorf("paresDoMapa com merge circular = %v, esperado apenas a chave a", pares)
+	}
+}
+
+func TestResolverAlias(t *testing.T) {
+	mapa := mapaDeTeste(t, "a: &x valor\nb: *x\n")
+	if valor, _ := texto(valorDoMapa(mapa, "b")); valor != "valor" {
+		t.Errorf("alias resolvido = %q, esperado valor", valor)
+	}
+
+	// aliases em ciclo param no limite e retornam nil
+	a := &yaml.Node{Kind: yaml.AliasNode}
+	b := &yaml.Node{Kind: yaml.AliasNode, Alias: a}
+	a.Alias = b
+	if resolverAlias(a) != nil {
+		t.Error("resolverAlias de um ciclo deve ser nil")
+	}
+	if resolverAlias(nil) != nil {
+		t.Error("resolverAlias(nil) deve ser nil")
+	}
+	if resolverAlias(&yaml.Node{Kind: yaml.AliasNode}) != nil {
+		t.Error("resolverAlias de um alias sem destino deve ser nil")
+	}
+}
+
+func TestItensDaLista(t *testing.T) {
+	mapa := mapaDeTeste(t, "item: &i x\nlista: [a, *i, {b: 1}]\nvazia: []\nnulo: null\n")
+	itens := itensDaLista(valorDoMapa(mapa, "lista"))
+	if len(itens) != 3 || itens[1].Value != "x" || itens[2].Kind != yaml.MappingNode {
+		t.Errorf("itensDaLista = %v, esperado [a x {b: 1}]", itens)
+	}
+	for _, chave := range []string{"vazia", "nulo", "item", "ausente"} {
+		if itens := itensDaLista(valorDoMapa(mapa, chave)); len(itens) != 0 {
+			t.Errorf("itensDaLista(%s) = %v, esperado vazio", chave, itens)
+		}
+	}
+}
+
+func TestEscalares(t *testing.T) {
+	mapa := mapaDeTeste(t, "texto: abc\nnumero: ' 42 '\ndecimal: 1.5\nverdadeiro: true\nfalso: false\nmapa: {a: 1}\n")
+
+	casos := []struct {
+		chave            string
+		texto            string
+		textoOk          bool
+		inteiro          int
+		inteiroOk        bool
+		booleano, boolOk bool
+	}{
+		{"texto", "abc", true, 0, false, false, false},
+		{"numero", " 42 ", true, 42, true, false, false},
+		{"decimal", "1.5", true, 0, false, false, false},
+		{"verdadeiro", "true", true, 0, false, true, true},
+		{"falso", "false", true, 0, false, false, true},
+		{"mapa", "", false, 0, false, false, false},
+		{"ausente", "", false, 0, false, false, false},
+	}
+	for _, c := range casos {
+		node := valorDoMapa(mapa, c.chave)
+		if valor, ok := texto(node); valor != c.texto || ok != c.textoOk {
+			t.Errorf("texto(%s) = %q, %v; esperado %q, %v", c.chave, valor, ok, c.texto, c.textoOk)
+		}
+		if valor, ok := inteiro(node); valor != c.inteiro || ok != c.inteiroOk {
+			t.Errorf("inteiro(%s) = %d, %v; esperado %d, %v", c.chave, valor, ok, c.inteiro, c.inteiroOk)
+		}
+		if valor, ok := booleano(node); valor != c.booleano || ok != c.boolOk {
+			t.Errorf("booleano(%s) = %v, %v; esperado %v, %v", c.chave, valor, ok, c.booleano, c.boolOk)
+		}
+	}
+}